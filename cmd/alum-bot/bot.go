@@ -1,15 +1,27 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/FGasquez/alum-bot/internal/cache"
 	holidaysCmd "github.com/FGasquez/alum-bot/internal/commands/holiday"
 	"github.com/FGasquez/alum-bot/internal/config"
+	"github.com/FGasquez/alum-bot/internal/health"
+	"github.com/FGasquez/alum-bot/internal/leader"
+	"github.com/FGasquez/alum-bot/internal/lifecycle"
+	"github.com/FGasquez/alum-bot/internal/logging"
 	"github.com/FGasquez/alum-bot/internal/messages"
-	"github.com/FGasquez/alum-bot/internal/types"
+	"github.com/FGasquez/alum-bot/internal/metrics"
+	"github.com/FGasquez/alum-bot/internal/responder"
+	"github.com/FGasquez/alum-bot/internal/server"
+	"github.com/FGasquez/alum-bot/internal/sources"
+	"github.com/FGasquez/alum-bot/internal/status"
 	"github.com/bwmarrin/discordgo"
 	"github.com/sirupsen/logrus"
 )
@@ -19,13 +31,53 @@ var commands = []*discordgo.ApplicationCommand{
 	&holidaysCmd.HowManyDaysToHoliday,
 	&holidaysCmd.HolidaysOfMonth,
 	&holidaysCmd.HolidaysLargeCommands,
+	&holidaysCmd.LongWeekendsCommand,
+	&holidaysCmd.RegionCommand,
+	&holidaysCmd.CustomHolidayCommand,
+	&holidaysCmd.CountryCommand,
+	&holidaysCmd.PrivacyCommand,
+	&holidaysCmd.RemindCommand,
+	&holidaysCmd.MessagesCommand,
+	&holidaysCmd.HolidayInfoCommand,
 }
 
-var commandHandlers = map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate){
-	holidaysCmd.HolidaysCommandName:      holidaysCmd.HolidaysCommandHandlers,
-	holidaysCmd.DaysLeftToHolidayName:    holidaysCmd.HowManyDaysToHolidayHandlers,
-	holidaysCmd.HolidaysOfMonthName:      holidaysCmd.HolidaysOfMonthHandlers,
-	holidaysCmd.HolidaysLargeCommandName: holidaysCmd.HolidayLargeCommandHandlers,
+var autocompleteHandlers = map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate){
+	holidaysCmd.HolidaysCommandName:      holidaysCmd.AutocompleteHandlers,
+	holidaysCmd.DaysLeftToHolidayName:    holidaysCmd.AutocompleteHandlers,
+	holidaysCmd.HolidaysOfMonthName:      holidaysCmd.AutocompleteHandlers,
+	holidaysCmd.HolidaysLargeCommandName: holidaysCmd.AutocompleteHandlers,
+	holidaysCmd.LongWeekendsCommandName:  holidaysCmd.AutocompleteHandlers,
+	holidaysCmd.RegionCommandName:        holidaysCmd.AutocompleteHandlers,
+	holidaysCmd.CountryCommandName:       holidaysCmd.AutocompleteHandlers,
+	holidaysCmd.RemindCommandName:        holidaysCmd.AutocompleteHandlers,
+	holidaysCmd.MessagesCommandName:      holidaysCmd.AutocompleteHandlers,
+	holidaysCmd.HolidayInfoCommandName:   holidaysCmd.AutocompleteHandlers,
+}
+
+// handleCommand runs the handler of a slash command or modal submit with a
+// logger of the interaction, recording its metrics and latency under name
+func handleCommand(s *discordgo.Session, i *discordgo.InteractionCreate, name string, handler holidaysCmd.Handler) {
+	start := time.Now()
+	ctx := holidaysCmd.InteractionContext(i)
+	log := logging.FromContext(ctx)
+	r := responder.New(ctx, s, i)
+	defer func() {
+		latency := time.Since(start)
+		log = log.WithField("latency_ms", latency.Milliseconds())
+		if p := recover(); p != nil {
+			log.Errorf("Command handler panicked: %v", p)
+			metrics.CommandErrors.WithLabelValues(name).Inc()
+		} else if r.Failed() {
+			log.Warn("Command answered with an error")
+			metrics.CommandErrors.WithLabelValues(name).Inc()
+		} else {
+			log.Info("Command answered")
+		}
+		metrics.CommandInvocations.WithLabelValues(name).Inc()
+		metrics.CommandDuration.WithLabelValues(name).Observe(latency.Seconds())
+	}()
+
+	handler(ctx, r, i)
 }
 
 func removeAllCommands(dg *discordgo.Session, guilds []string) {
@@ -46,75 +98,208 @@ func removeAllCommands(dg *discordgo.Session, guilds []string) {
 	}
 }
 
-func pruneCommands() {
-	token := config.GetToken()
+// botToken reads and validates the bot token, so a wrong one fails before connecting
+func botToken() (string, error) {
+	token, err := config.ReadToken()
+	if err != nil {
+		return "", err
+	}
+	return token, config.ValidateToken(token)
+}
+
+func pruneCommands() error {
+	token, err := botToken()
+	if err != nil {
+		return fmt.Errorf("invalid Discord token: %w", err)
+	}
 	testGuilds := config.GetTestGuilds()
 
 	dg, err := discordgo.New("Bot " + token)
 	if err != nil {
-		logrus.WithError(err).Error("Error creating Discord session")
-		return
+		return fmt.Errorf("creating Discord session: %w", err)
 	}
 
 	err = dg.Open()
 	if err != nil {
-		logrus.WithError(err).Error("Error opening Discord session")
-		return
+		return fmt.Errorf("opening Discord session: %w", err)
 	}
 	defer dg.Close()
 
 	removeAllCommands(dg, testGuilds)
+	return nil
 }
 
-func setActivityStatus(dg *discordgo.Session, message string) {
-
-	logrus.WithField("message", message).Debug("Setting activity status")
-	err := dg.UpdateStatusComplex(discordgo.UpdateStatusData{
-		Activities: []*discordgo.Activity{
-			{
-				Name:  message,
-				Type:  discordgo.ActivityTypeCustom,
-				State: message,
-			},
-		},
-	})
-	if err != nil {
-		logrus.WithError(err).Error("Error setting activity status")
+// runBot runs the bot until SIGINT or SIGTERM, the error reports why it could not start or run
+func runBot() error {
+	sources.RegisterDir(config.GetSourcesDir())
+
+	// a template referencing a missing field would only fail when it is rendered
+	if err := messages.ValidateAll(); err != nil {
+		return err
 	}
-}
 
-func runBot() {
-	token := config.GetToken()
-	testGuilds := config.GetTestGuilds()
+	token, err := botToken()
+	if err != nil {
+		return fmt.Errorf("invalid Discord token: %w", err)
+	}
 
 	dg, err := discordgo.New("Bot " + token)
 	if err != nil {
-		logrus.WithError(err).Error("Error creating Discord session")
-		return
+		return fmt.Errorf("creating Discord session: %w", err)
+	}
+	if err := configureShard(dg); err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
+	defer cancel()
+
+	return serve(ctx, dg, serveOptions{
+		TestGuilds: config.GetTestGuilds(),
+		Cache:      cache.Default(),
+	})
+}
+
+// serveOptions are what serve runs against, the tests replace the sources and
+// the cache to run without network access
+type serveOptions struct {
+	// TestGuilds are the guilds the commands are registered in
+	TestGuilds []string
+	// Cache is refreshed in the background while serving
+	Cache *cache.Cache
+	// Sources replace the registered holiday sources of their countries
+	Sources map[string]sources.Provider
+}
+
+// configureShard sets the shard of the session, asking Discord for the
+// recommended number of shards when shard-count is auto
+func configureShard(dg *discordgo.Session) error {
+	count := config.GetShardCount()
+	if count == 0 {
+		gateway, err := dg.GatewayBot()
+		if err != nil {
+			return fmt.Errorf("getting the recommended shard count: %w", err)
+		}
+		count = gateway.Shards
+	}
+
+	id := config.GetShardID()
+	if id >= count {
+		return fmt.Errorf("shard-id %d is out of the %d shards", id, count)
+	}
+
+	dg.ShardID, dg.ShardCount = id, count
+	logrus.WithFields(logrus.Fields{"shard": id, "shards": count}).Info("Configured shard")
+	return nil
+}
+
+// serve connects the session, registers the commands in the given guilds and
+// runs the background workers until ctx is cancelled or one of them fails.
+// On shutdown the workers are stopped, the interactions in progress are
+// drained for up to shutdown-timeout, and then the session is closed.
+func serve(ctx context.Context, dg *discordgo.Session, options serveOptions) error {
+	for country, provider := range options.Sources {
+		sources.Register(country, provider)
 	}
+	manager, ctx := lifecycle.New(ctx)
 
 	dg.AddHandler(func(s *discordgo.Session, i *discordgo.InteractionCreate) {
-		if i.Type == discordgo.InteractionApplicationCommand {
-			if handler, ok := commandHandlers[i.ApplicationCommandData().Name]; ok {
+		done, ok := manager.Track()
+		if !ok {
+			logrus.Debug("Ignoring interaction received while shutting down")
+			return
+		}
+		defer done()
+
+		switch i.Type {
+		case discordgo.InteractionApplicationCommand:
+			name := i.ApplicationCommandData().Name
+			if handler, ok := holidaysCmd.Handlers[name]; ok {
+				handleCommand(s, i, name, handler)
+			} else {
+				logrus.Warnf("No handler for command: %s", name)
+			}
+		case discordgo.InteractionModalSubmit:
+			name, _, _ := strings.Cut(i.ModalSubmitData().CustomID, ":")
+			if handler, ok := holidaysCmd.ModalHandlers[name]; ok {
+				handleCommand(s, i, name, handler)
+			} else {
+				logrus.Warnf("No handler for modal: %s", i.ModalSubmitData().CustomID)
+			}
+		case discordgo.InteractionApplicationCommandAutocomplete:
+			if handler, ok := autocompleteHandlers[i.ApplicationCommandData().Name]; ok {
 				handler(s, i)
 			} else {
-				logrus.Warnf("No handler for command: %s", i.ApplicationCommandData().Name)
+				logrus.Warnf("No autocomplete handler for command: %s", i.ApplicationCommandData().Name)
 			}
 		}
 	})
 
+	dg.AddHandler(func(s *discordgo.Session, c *discordgo.Connect) {
+		health.SetConnected(true)
+	})
+	dg.AddHandler(func(s *discordgo.Session, d *discordgo.Disconnect) {
+		health.SetConnected(false)
+	})
+
 	dg.Identify.Intents = discordgo.IntentsGuildMessages
 
-	err = dg.Open()
-	if err != nil {
-		logrus.WithError(err).Error("Error opening connection")
-		return
+	// the HTTP server starts first, so the health checks answer while connecting
+	manager.Go("http", server.New(config.GetHTTPAddr()).Run)
+	// the cache keeps refreshing until the interactions are drained, which may need it
+	cacheCtx, stopCache := context.WithCancel(context.Background())
+	defer stopCache()
+	manager.Go("cache", func(context.Context) error {
+		return options.Cache.Run(cacheCtx)
+	})
+
+	if err := start(dg, options.TestGuilds); err != nil {
+		manager.Stop()
+		stopCache()
+		manager.Wait()
+		return err
+	}
+
+	rotator := status.NewRotator(dg, holidaysCmd.GuildScope(config.GetStatusGuild()))
+	manager.Go("status", rotator.Run)
+	// every shard sets its own status, Discord shows the presence of the
+	// shard a guild belongs to, but only the leader sends the reminders
+	elector := leader.New(config.GetLeaderLock(), config.GetLeaderLease())
+	manager.Go("reminders", func(ctx context.Context) error {
+		return elector.Run(ctx, func(ctx context.Context) error {
+			return holidaysCmd.RunReminders(ctx, dg)
+		})
+	})
+
+	logrus.Info("Bot is now running. Press CTRL-C to exit.")
+	<-ctx.Done()
+	logrus.Info("Graceful shutdown")
+
+	if !manager.Drain(config.GetShutdownTimeout()) {
+		logrus.WithField("timeout", config.GetShutdownTimeout()).Warn("Commands still in progress after the shutdown timeout")
+	}
+	stopCache()
+	err := manager.Wait()
+	if closeErr := dg.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// start opens the session and registers the commands missing in the given
+// guilds, the commands are registered by the first shard only
+func start(dg *discordgo.Session, testGuilds []string) error {
+	if err := dg.Open(); err != nil {
+		return fmt.Errorf("opening connection: %w", err)
+	}
+	if dg.ShardID != 0 {
+		return nil
 	}
 
 	existingCommands, err := dg.ApplicationCommands(dg.State.User.ID, "")
 	if err != nil {
-		logrus.WithError(err).Error("Error fetching existing commands")
-		return
+		dg.Close()
+		return fmt.Errorf("fetching existing commands: %w", err)
 	}
 
 	existingCommandNames := make(map[string]bool)
@@ -140,28 +325,5 @@ func runBot() {
 		}
 	}
 
-	DaysLeft, _, _ := holidaysCmd.DaysLeft(true, false)
-	logrus.Infof("Time to next holiday: %d days", DaysLeft)
-
-	go func() {
-		DaysLeft, _, _ := holidaysCmd.DaysLeft(true, false)
-		setActivityStatus(dg, messages.TemplateMessage(messages.GetMessage(messages.MessageKeys.ActivityStatus), types.TemplateValues{DaysLeft: DaysLeft}))
-
-		ticker := time.NewTicker(10 * time.Minute)
-		defer ticker.Stop()
-
-		for range ticker.C {
-			DaysLeft, _, _ := holidaysCmd.DaysLeft(true, false)
-			setActivityStatus(dg, messages.TemplateMessage(messages.GetMessage(messages.MessageKeys.ActivityStatus), types.TemplateValues{DaysLeft: DaysLeft}))
-		}
-	}()
-
-	logrus.Info("Bot is now running. Press CTRL-C to exit.")
-	sc := make(chan os.Signal, 1)
-	signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
-	<-sc
-	// Cleanly close down the Discord session.
-	logrus.Info("Graceful shutdown")
-	setActivityStatus(dg, "")
-	dg.Close()
+	return nil
 }
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FGasquez/alum-bot/internal/cache"
+	"github.com/FGasquez/alum-bot/internal/commands/holiday/holidaytest"
+	"github.com/FGasquez/alum-bot/internal/discordtest"
+	"github.com/FGasquez/alum-bot/internal/sources"
+	"github.com/FGasquez/alum-bot/internal/types"
+	"github.com/spf13/viper"
+)
+
+const waitTimeout = 5 * time.Second
+
+// TestServe runs the bot against the local Discord stand-in and in-memory
+// holidays: it registers the commands, sets the status, answers a command and
+// shuts down when its context is cancelled.
+func TestServe(t *testing.T) {
+	viper.Set("messages-dir", "../../messages")
+	defer viper.Set("messages-dir", nil)
+
+	h := holidaytest.New(time.Date(2025, 7, 1, 10, 0, 0, 0, time.Local), t.TempDir())
+	defer h.Close()
+
+	fake := discordtest.NewServer()
+	defer fake.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, fake.Session(), serveOptions{
+			TestGuilds: []string{"guild"},
+			Cache:      cache.New(t.TempDir(), time.Hour, 0),
+			Sources: map[string]sources.Provider{
+				"AR": holidaytest.Source{ByYear: map[int][]types.Holiday{
+					2025: {{Date: "2025-07-09", Type: "inamovible", Name: "Día de la Independencia"}},
+					2026: {{Date: "2026-07-09", Type: "inamovible", Name: "Día de la Independencia"}},
+				}},
+			},
+		})
+	}()
+
+	t.Run("registers the commands", func(t *testing.T) {
+		registered := fake.Wait(waitTimeout, func() bool {
+			return len(fake.Commands("guild")) == len(commands)
+		})
+		if !registered {
+			t.Fatalf("%d commands registered, want %d", len(fake.Commands("guild")), len(commands))
+		}
+	})
+
+	t.Run("sets the status", func(t *testing.T) {
+		updated := fake.Wait(waitTimeout, func() bool { return len(fake.Statuses()) > 0 })
+		if !updated {
+			t.Fatal("no presence update was sent")
+		}
+		activities := fake.Statuses()[0].Activities
+		if len(activities) == 0 || !strings.Contains(activities[0].State, "8 days") {
+			t.Errorf("status %+v, want the 8 days left", activities)
+		}
+	})
+
+	t.Run("answers a command", func(t *testing.T) {
+		i := holidaytest.Interaction("next-holiday").Interaction
+		if err := fake.Interact(i); err != nil {
+			t.Fatal(err)
+		}
+
+		var reply string
+		answered := fake.Wait(waitTimeout, func() bool {
+			var ok bool
+			reply, ok = fake.Reply(i)
+			return ok
+		})
+		if !answered {
+			t.Fatal("the command was not answered")
+		}
+		if want := "**Día de la Independencia**"; !strings.Contains(reply, want) {
+			t.Errorf("reply %q does not contain %q", reply, want)
+		}
+	})
+
+	t.Run("shuts down", func(t *testing.T) {
+		cancel()
+		select {
+		case err := <-served:
+			if err != nil {
+				t.Fatalf("serve returned %v", err)
+			}
+		case <-time.After(waitTimeout):
+			t.Fatal("serve did not return after the context was cancelled")
+		}
+		if !fake.Wait(waitTimeout, fake.Disconnected) {
+			t.Error("the gateway connection was not closed")
+		}
+	})
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/FGasquez/alum-bot/internal/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+func configCommand() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the configuration",
+	}
+
+	configCmd.AddCommand(&cobra.Command{
+		Use:   "print",
+		Short: "Print the effective configuration as YAML, with the secrets redacted",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return printConfig()
+		},
+	})
+
+	return configCmd
+}
+
+// printConfig writes the merged flags, environment, config file and defaults
+func printConfig() error {
+	effective := yaml.MapSlice{}
+	for _, setting := range config.Effective() {
+		effective = append(effective, yaml.MapItem{Key: setting.Key, Value: setting.Value})
+	}
+
+	out, err := yaml.Marshal(effective)
+	if err != nil {
+		return err
+	}
+
+	if file := config.File(); file != "" {
+		fmt.Fprintf(os.Stdout, "# config file: %s\n", file)
+	}
+	_, err = os.Stdout.Write(out)
+	return err
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/FGasquez/alum-bot/internal/config"
+)
+
+const healthcheckTimeout = 5 * time.Second
+
+// healthcheck queries the health endpoint of the bot listening on http-addr
+func healthcheck(ready bool) error {
+	addr := config.GetHTTPAddr()
+	if addr == "" {
+		return fmt.Errorf("the HTTP server is disabled, set --http-addr or HTTP_ADDR")
+	}
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid HTTP address %q: %w", addr, err)
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+
+	path := "/healthz"
+	if ready {
+		path = "/readyz"
+	}
+
+	client := &http.Client{Timeout: healthcheckTimeout}
+	resp, err := client.Get("http://" + net.JoinHostPort(host, port) + path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("%s returned %s: %s", path, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -1,10 +1,13 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"os"
+	_ "time/tzdata"
 
-	"github.com/sirupsen/logrus"
+	"github.com/FGasquez/alum-bot/internal/config"
+	"github.com/FGasquez/alum-bot/internal/logging"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -17,24 +20,69 @@ var (
 	PruneCommands  bool
 )
 
-func init() {
-	logrus.SetFormatter(&logrus.JSONFormatter{})
-	logrus.SetOutput(os.Stdout)
-	logrus.SetLevel(logrus.DebugLevel)
-}
-
 func main() {
 	rootCmd := &cobra.Command{
-		Use:   "alum-bot",
-		Short: "Discord bot for keeping track of holidays in Argentina",
-		Run: func(cmd *cobra.Command, args []string) {
-			runBot()
+		Use:           "alum-bot",
+		Short:         "Discord bot for keeping track of holidays",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			configFile, _ := cmd.Flags().GetString("config")
+			if configFile == "" {
+				configFile = os.Getenv("CONFIG_FILE")
+			}
+			if err := config.Load(configFile); err != nil {
+				return err
+			}
+			if err := config.Validate(); err != nil {
+				return err
+			}
+			if err := config.ApplyTimezone(); err != nil {
+				return err
+			}
+			if err := logging.Configure(config.GetLogLevel(), config.GetLogFormat(), os.Stdout); err != nil {
+				return err
+			}
+			// the token may be invalid, it is hidden from the logs anyway
+			token, _ := config.ReadToken()
+			logging.Redact(token)
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBot()
 		},
 	}
 
-	rootCmd.PersistentFlags().StringP("token", "t", "", "Bot token (default: DISCORD_TOKEN)")
+	rootCmd.PersistentFlags().StringP("config", "c", "", "Path to a YAML or TOML config file, flags and environment variables take precedence over it (default: CONFIG_FILE)")
+	rootCmd.PersistentFlags().StringP("token", "t", "", "Bot token, prefer --token-file since flags show up in process listings (default: DISCORD_TOKEN)")
+	rootCmd.PersistentFlags().String("token-file", "", "File holding the bot token, e.g. a docker or kubernetes secret (default: DISCORD_TOKEN_FILE)")
 	rootCmd.PersistentFlags().StringSliceP("test-guilds", "g", []string{}, "List of test guild IDs (default: TEST_GUILD_ID)")
 	rootCmd.PersistentFlags().String("messages-file", "", "Path to messages file (default: '')")
+	rootCmd.PersistentFlags().String("messages-dir", "", "Directory with the messages catalog of each locale, named <locale>.yaml (default: MESSAGES_DIR or 'messages')")
+	rootCmd.PersistentFlags().String("locale", "", "Locale of the messages, its catalog is used below the messages file (default: LOCALE or 'en')")
+	rootCmd.PersistentFlags().String("timezone", "", "Timezone of the dates, e.g. America/Argentina/Buenos_Aires (default: TZ or the system timezone)")
+	rootCmd.PersistentFlags().String("data-dir", "", "Directory where guild and user settings are stored (default: DATA_DIR or 'data')")
+	rootCmd.PersistentFlags().String("status-guild", "", "Guild whose region and days off are used for the activity status (default: STATUS_GUILD_ID or the first test guild)")
+	rootCmd.PersistentFlags().String("sources-dir", "", "Directory with the holiday files of other countries (default: SOURCES_DIR or 'holidays')")
+	rootCmd.PersistentFlags().String("default-country", "", "Country used when neither the user nor the guild chose one (default: DEFAULT_COUNTRY or 'AR')")
+	rootCmd.PersistentFlags().Duration("api-timeout", 0, "Timeout of each request to the holiday API (default: API_TIMEOUT or 5s)")
+	rootCmd.PersistentFlags().Int("api-retries", 0, "Retries after a holiday API timeout or server error (default: API_RETRIES or 2)")
+	rootCmd.PersistentFlags().Duration("api-backoff", 0, "Wait before the first retry, doubled on every retry (default: API_BACKOFF or 500ms)")
+	rootCmd.PersistentFlags().String("cache-dir", "", "Directory where the holiday API responses are cached (default: CACHE_DIR or the system temp dir)")
+	rootCmd.PersistentFlags().Duration("cache-ttl", 0, "How long cached holidays are fresh (default: CACHE_TTL or 24h)")
+	rootCmd.PersistentFlags().Duration("cache-refresh-before", 0, "Refresh cached holidays in the background when they expire within this time (default: CACHE_REFRESH_BEFORE or 1h)")
+	rootCmd.PersistentFlags().String("regions-dir", "", "Directory with the regional holidays files (default: REGIONS_DIR or 'regions')")
+	rootCmd.PersistentFlags().StringSlice("status-messages", []string{}, "Message keys the activity status rotates through (default: STATUS_MESSAGES or 'activityStatus')")
+	rootCmd.PersistentFlags().Duration("status-interval", 0, "Time each activity status is shown before rotating (default: STATUS_INTERVAL or 10m)")
+	rootCmd.PersistentFlags().String("http-addr", "", "Address of the HTTP server exposing /metrics, /healthz and /readyz, e.g. ':8080' (default: HTTP_ADDR, disabled when empty)")
+	rootCmd.PersistentFlags().Duration("reminder-interval", 0, "How often the reminders are checked (default: REMINDER_INTERVAL or 15m)")
+	rootCmd.PersistentFlags().Int("shard-id", 0, "Shard of this instance, from 0 to shard-count - 1 (default: SHARD_ID or 0)")
+	rootCmd.PersistentFlags().String("shard-count", "", "Number of shards, or auto to use the one recommended by Discord (default: SHARD_COUNT or 1)")
+	rootCmd.PersistentFlags().String("leader-lock", "", "Lock file shared by the instances to elect the one sending the reminders (default: LEADER_LOCK, disabled when empty)")
+	rootCmd.PersistentFlags().Duration("leader-lease", 0, "Time without renewing the leader lock after which another instance takes over (default: LEADER_LEASE or 30s)")
+	rootCmd.PersistentFlags().String("log-level", "", "Log level: trace, debug, info, warn or error (default: LOG_LEVEL or 'debug')")
+	rootCmd.PersistentFlags().String("log-format", "", "Log format: json or text (default: LOG_FORMAT or 'json')")
+	rootCmd.PersistentFlags().String("status-activity-type", "", "Activity type of the status: custom, playing, watching, listening or competing (default: STATUS_ACTIVITY_TYPE or 'custom')")
 
 	if err := viper.BindPFlags(rootCmd.PersistentFlags()); err != nil {
 		log.Fatal(err)
@@ -43,11 +91,26 @@ func main() {
 	rootCmd.AddCommand(&cobra.Command{
 		Use:   "prune-commands",
 		Short: "Prune all commands and exit",
-		Run: func(cmd *cobra.Command, args []string) {
-			pruneCommands()
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return pruneCommands()
 		},
 	})
 
+	healthcheckCmd := &cobra.Command{
+		Use:   "healthcheck",
+		Short: "Check the health endpoint of a running bot, exiting 1 when it is unhealthy",
+		Run: func(cmd *cobra.Command, args []string) {
+			ready, _ := cmd.Flags().GetBool("ready")
+			if err := healthcheck(ready); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		},
+	}
+	healthcheckCmd.Flags().Bool("ready", false, "Check /readyz instead of /healthz")
+	rootCmd.AddCommand(healthcheckCmd)
+	rootCmd.AddCommand(configCommand())
+
 	viper.BindPFlags(rootCmd.PersistentFlags())
 	if err := rootCmd.Execute(); err != nil {
 		log.Fatal(err)
@@ -0,0 +1,320 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/FGasquez/alum-bot/internal/config"
+	"github.com/FGasquez/alum-bot/internal/logging"
+	"github.com/FGasquez/alum-bot/internal/metrics"
+	"github.com/FGasquez/alum-bot/internal/types"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
+)
+
+// refreshTimeout bounds the upstream fetches, which are not tied to any interaction
+const refreshTimeout = time.Minute
+
+// FetchFunc retrieves the holidays stored under a key from upstream
+type FetchFunc func(ctx context.Context) ([]types.Holiday, error)
+
+type entry struct {
+	holidays  []types.Holiday
+	fetchedAt time.Time
+	// generation changes every time the entry is stored in memory
+	generation uint64
+}
+
+// Cache keeps holidays in memory and on disk. Entries older than TTL are
+// fetched again, entries closer than RefreshBefore to expiring are refreshed
+// in the background, and expired entries are still served when upstream fails.
+// Concurrent fetches of the same key share a single upstream request.
+type Cache struct {
+	Dir           string
+	TTL           time.Duration
+	RefreshBefore time.Duration
+
+	mu        sync.RWMutex
+	entries   map[string]entry
+	lastFetch time.Time
+	group     singleflight.Group
+	// generations counts the entries stored in memory
+	generations uint64
+
+	// stopped is closed by Run to cancel the background refreshes
+	stopped   chan struct{}
+	refreshes sync.WaitGroup
+}
+
+var (
+	defaultOnce  sync.Once
+	defaultCache *Cache
+)
+
+// Default returns the cache configured from the cache-* settings
+func Default() *Cache {
+	defaultOnce.Do(func() {
+		defaultCache = New(config.GetCacheDir(), config.GetCacheTTL(), config.GetCacheRefreshBefore())
+	})
+	return defaultCache
+}
+
+func New(dir string, ttl time.Duration, refreshBefore time.Duration) *Cache {
+	return &Cache{
+		Dir:           dir,
+		TTL:           ttl,
+		RefreshBefore: refreshBefore,
+		entries:       map[string]entry{},
+		stopped:       make(chan struct{}),
+	}
+}
+
+// Run lets the cache refresh entries in the background until ctx is
+// cancelled, then cancels and waits for the refreshes in progress
+func (c *Cache) Run(ctx context.Context) error {
+	<-ctx.Done()
+	c.mu.Lock()
+	select {
+	case <-c.stopped:
+	default:
+		close(c.stopped)
+	}
+	c.mu.Unlock()
+	c.refreshes.Wait()
+	return nil
+}
+
+// Get returns the holidays stored under key, calling fetch when they are missing or expired
+func (c *Cache) Get(ctx context.Context, key string, fetch FetchFunc) ([]types.Holiday, error) {
+	cached, layer, ok := c.lookup(key)
+	if ok && c.fresh(cached) {
+		metrics.CacheLookups.WithLabelValues(layer).Inc()
+		if c.expiring(cached) {
+			c.refresh(ctx, key, fetch)
+		}
+		return cached.holidays, nil
+	}
+	metrics.CacheLookups.WithLabelValues(metrics.CacheMiss).Inc()
+
+	// The fetch is detached from the cancellation of ctx, so a caller giving up
+	// does not fail the other callers waiting on the same key
+	results := c.group.DoChan(key, func() (interface{}, error) {
+		fetchCtx, cancel := c.detach(ctx)
+		defer cancel()
+		return c.fetch(fetchCtx, key, fetch)
+	})
+
+	var result singleflight.Result
+	select {
+	case result = <-results:
+	case <-ctx.Done():
+		result = singleflight.Result{Err: ctx.Err()}
+	}
+
+	if err := result.Err; err != nil {
+		if ok {
+			logging.FromContext(ctx).WithError(err).WithField("key", key).Warn("Failed to refresh holidays, serving stale cache")
+			metrics.CacheLookups.WithLabelValues(metrics.CacheStale).Inc()
+			return cached.holidays, nil
+		}
+		return nil, err
+	}
+
+	return result.Val.([]types.Holiday), nil
+}
+
+// Cached reports whether fresh holidays are stored under key, so Get will not reach upstream
+func (c *Cache) Cached(key string) bool {
+	cached, _, ok := c.lookup(key)
+	return ok && c.fresh(cached)
+}
+
+// Generation returns the generation of the holidays stored under key, which
+// changes whenever they are fetched or loaded again. It is false when Get would
+// reach upstream or refresh the entry, so callers must go through Get then.
+func (c *Cache) Generation(key string) (uint64, bool) {
+	c.mu.RLock()
+	cached, ok := c.entries[key]
+	c.mu.RUnlock()
+	if !ok || !c.fresh(cached) || c.expiring(cached) {
+		return 0, false
+	}
+	return cached.generation, true
+}
+
+// LastFetch returns when holidays were last fetched from upstream, zero if never
+func (c *Cache) LastFetch() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.lastFetch
+}
+
+// Ages returns how long ago each entry in memory was fetched
+func (c *Cache) Ages() map[string]time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ages := make(map[string]time.Duration, len(c.entries))
+	for key, e := range c.entries {
+		ages[key] = time.Since(e.fetchedAt)
+	}
+	return ages
+}
+
+// lookup returns the entry of key from memory, loading it from disk when
+// needed, and the layer it was found in
+func (c *Cache) lookup(key string) (entry, string, bool) {
+	c.mu.RLock()
+	cached, ok := c.entries[key]
+	c.mu.RUnlock()
+	if ok {
+		return cached, metrics.CacheMemoryHit, true
+	}
+
+	cached, err := c.readDisk(key)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logrus.WithError(err).WithField("key", key).Warn("Failed to read cache file")
+		}
+		return entry{}, "", false
+	}
+
+	c.mu.Lock()
+	// a fetch may have stored a newer entry meanwhile
+	if current, ok := c.entries[key]; ok && current.fetchedAt.After(cached.fetchedAt) {
+		cached = current
+	} else {
+		c.generations++
+		cached.generation = c.generations
+		c.entries[key] = cached
+	}
+	c.mu.Unlock()
+
+	return cached, metrics.CacheDiskHit, true
+}
+
+func (c *Cache) fresh(e entry) bool {
+	return time.Since(e.fetchedAt) < c.TTL
+}
+
+func (c *Cache) expiring(e entry) bool {
+	return time.Since(e.fetchedAt) >= c.TTL-c.RefreshBefore
+}
+
+// refresh fetches key in the background, without making the caller wait
+func (c *Cache) refresh(ctx context.Context, key string, fetch FetchFunc) {
+	c.mu.Lock()
+	select {
+	case <-c.stopped:
+		c.mu.Unlock()
+		return
+	default:
+	}
+	c.refreshes.Add(1)
+	c.mu.Unlock()
+
+	go func() {
+		defer c.refreshes.Done()
+		ctx, cancel := c.detach(ctx)
+		defer cancel()
+
+		_, err, _ := c.group.Do(key, func() (interface{}, error) {
+			return c.fetch(ctx, key, fetch)
+		})
+		if err != nil {
+			logging.FromContext(ctx).WithError(err).WithField("key", key).Warn("Background refresh of holidays failed")
+		}
+	}()
+}
+
+// detach returns a context for an upstream fetch that keeps the values of ctx
+// but not its cancellation, it is cancelled instead when the cache stops
+func (c *Cache) detach(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), refreshTimeout)
+	go func() {
+		select {
+		case <-c.stopped:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+func (c *Cache) fetch(ctx context.Context, key string, fetch FetchFunc) ([]types.Holiday, error) {
+	holidays, err := fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	fetched := entry{holidays: holidays, fetchedAt: time.Now()}
+	c.mu.Lock()
+	c.generations++
+	fetched.generation = c.generations
+	c.entries[key] = fetched
+	c.lastFetch = fetched.fetchedAt
+	c.mu.Unlock()
+
+	if err := c.writeDisk(key, fetched); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("key", key).Warn("Failed to write cache file")
+	}
+
+	return holidays, nil
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.Dir, fmt.Sprintf("holidays_%s.json", key))
+}
+
+func (c *Cache) readDisk(key string) (entry, error) {
+	path := c.path(key)
+	info, err := os.Stat(path)
+	if err != nil {
+		return entry{}, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return entry{}, err
+	}
+
+	var holidays []types.Holiday
+	if err := json.Unmarshal(data, &holidays); err != nil {
+		return entry{}, err
+	}
+
+	return entry{holidays: holidays, fetchedAt: info.ModTime()}, nil
+}
+
+// writeDisk stores the entry through a temporary file, so readers never see a partial write
+func (c *Cache) writeDisk(key string, e entry) error {
+	data, err := json.Marshal(e.holidays)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(c.Dir, ".holidays-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), c.path(key))
+}
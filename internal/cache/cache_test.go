@@ -0,0 +1,220 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/FGasquez/alum-bot/internal/types"
+)
+
+var (
+	independencia = []types.Holiday{{Date: "2025-07-09", Type: "inamovible", Name: "Día de la Independencia"}}
+	navidad       = []types.Holiday{{Date: "2025-12-25", Type: "inamovible", Name: "Navidad"}}
+	errUpstream   = errors.New("upstream down")
+)
+
+// fetcher counts the upstream calls and answers with holidays, or err when set
+type fetcher struct {
+	calls    atomic.Int32
+	mu       sync.Mutex
+	holidays []types.Holiday
+	err      error
+	// release, when set, blocks the calls until it is closed
+	release chan struct{}
+}
+
+func (f *fetcher) fetch(ctx context.Context) ([]types.Holiday, error) {
+	f.calls.Add(1)
+	if f.release != nil {
+		<-f.release
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.holidays, f.err
+}
+
+func (f *fetcher) set(holidays []types.Holiday, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.holidays, f.err = holidays, err
+}
+
+func name(holidays []types.Holiday) string {
+	if len(holidays) == 0 {
+		return ""
+	}
+	return holidays[0].Name
+}
+
+// eventually waits up to a second for condition
+func eventually(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within a second")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestGetSharesConcurrentFetches(t *testing.T) {
+	c := New(t.TempDir(), time.Hour, 0)
+	f := &fetcher{holidays: independencia, release: make(chan struct{})}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			holidays, err := c.Get(context.Background(), "AR_2025", f.fetch)
+			if err != nil || name(holidays) != "Día de la Independencia" {
+				t.Errorf("got %v, %v", holidays, err)
+			}
+		}()
+	}
+	eventually(t, func() bool { return f.calls.Load() > 0 })
+	time.Sleep(10 * time.Millisecond)
+	close(f.release)
+	wg.Wait()
+
+	if got := f.calls.Load(); got != 1 {
+		t.Errorf("%d upstream calls, want 1", got)
+	}
+}
+
+func TestGetCancelledCallerDoesNotFailOthers(t *testing.T) {
+	c := New(t.TempDir(), time.Hour, 0)
+	f := &fetcher{holidays: independencia, release: make(chan struct{})}
+
+	waiting := make(chan error)
+	go func() {
+		_, err := c.Get(context.Background(), "AR_2025", f.fetch)
+		waiting <- err
+	}()
+	eventually(t, func() bool { return f.calls.Load() > 0 })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.Get(ctx, "AR_2025", f.fetch); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want the deadline", err)
+	}
+
+	close(f.release)
+	if err := <-waiting; err != nil {
+		t.Errorf("the other caller failed: %v", err)
+	}
+}
+
+func TestGetServesStaleWhenUpstreamFails(t *testing.T) {
+	c := New(t.TempDir(), 20*time.Millisecond, 0)
+	f := &fetcher{holidays: independencia}
+	if _, err := c.Get(context.Background(), "AR_2025", f.fetch); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	f.set(nil, errUpstream)
+	holidays, err := c.Get(context.Background(), "AR_2025", f.fetch)
+	if err != nil || name(holidays) != "Día de la Independencia" {
+		t.Errorf("got %v, %v, want the stale holidays", holidays, err)
+	}
+	if got := f.calls.Load(); got != 2 {
+		t.Errorf("%d upstream calls, want 2", got)
+	}
+}
+
+func TestGetFailsWithoutCachedHolidays(t *testing.T) {
+	c := New(t.TempDir(), time.Hour, 0)
+	f := &fetcher{err: errUpstream}
+	if _, err := c.Get(context.Background(), "AR_2025", f.fetch); !errors.Is(err, errUpstream) {
+		t.Errorf("got %v, want %v", err, errUpstream)
+	}
+}
+
+func TestGetRefreshesExpiringInBackground(t *testing.T) {
+	// every entry is expiring, so every hit refreshes it
+	c := New(t.TempDir(), time.Hour, time.Hour)
+	ctx, stop := context.WithCancel(context.Background())
+	stopped := make(chan error)
+	go func() { stopped <- c.Run(ctx) }()
+
+	f := &fetcher{holidays: independencia}
+	if _, err := c.Get(context.Background(), "AR_2025", f.fetch); err != nil {
+		t.Fatal(err)
+	}
+
+	f.set(navidad, nil)
+	f.release = make(chan struct{})
+	holidays, err := c.Get(context.Background(), "AR_2025", f.fetch)
+	if err != nil || name(holidays) != "Día de la Independencia" {
+		t.Errorf("got %v, %v, want the cached holidays without waiting", holidays, err)
+	}
+	close(f.release)
+	eventually(t, func() bool { return f.calls.Load() == 2 })
+
+	// the refreshed holidays are served without reaching upstream again
+	eventually(t, func() bool {
+		holidays, _ := c.Get(context.Background(), "AR_2025", func(context.Context) ([]types.Holiday, error) {
+			return nil, errUpstream
+		})
+		return name(holidays) == "Navidad"
+	})
+
+	stop()
+	if err := <-stopped; err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGetReloadsFromDisk(t *testing.T) {
+	dir := t.TempDir()
+	f := &fetcher{holidays: independencia}
+	if _, err := New(dir, time.Hour, 0).Get(context.Background(), "AR_2025", f.fetch); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "holidays_AR_2025.json" {
+		t.Errorf("files %v, want only holidays_AR_2025.json", entries)
+	}
+
+	restarted := New(dir, time.Hour, 0)
+	if !restarted.Cached("AR_2025") {
+		t.Error("the holidays on disk are not cached")
+	}
+	holidays, err := restarted.Get(context.Background(), "AR_2025", f.fetch)
+	if err != nil || name(holidays) != "Día de la Independencia" {
+		t.Errorf("got %v, %v", holidays, err)
+	}
+	if got := f.calls.Load(); got != 1 {
+		t.Errorf("%d upstream calls, want 1", got)
+	}
+}
+
+func TestGetFetchesExpiredDiskEntry(t *testing.T) {
+	dir := t.TempDir()
+	f := &fetcher{holidays: independencia}
+	if _, err := New(dir, time.Hour, 0).Get(context.Background(), "AR_2025", f.fetch); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "holidays_AR_2025.json"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	f.set(navidad, nil)
+	holidays, err := New(dir, time.Hour, 0).Get(context.Background(), "AR_2025", f.fetch)
+	if err != nil || name(holidays) != "Navidad" {
+		t.Errorf("got %v, %v, want the holidays fetched again", holidays, err)
+	}
+}
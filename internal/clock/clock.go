@@ -0,0 +1,33 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+var (
+	mu  sync.RWMutex
+	now = time.Now
+)
+
+// Now returns the current time, or the time set with Set
+func Now() time.Time {
+	mu.RLock()
+	defer mu.RUnlock()
+	return now()
+}
+
+// Set replaces the source of the current time, nil restores the system clock
+func Set(source func() time.Time) {
+	mu.Lock()
+	defer mu.Unlock()
+	if source == nil {
+		source = time.Now
+	}
+	now = source
+}
+
+// Fixed returns a source that always reports t
+func Fixed(t time.Time) func() time.Time {
+	return func() time.Time { return t }
+}
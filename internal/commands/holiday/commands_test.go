@@ -0,0 +1,151 @@
+package holidays_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FGasquez/alum-bot/internal/commands/holiday/holidaytest"
+	"github.com/FGasquez/alum-bot/internal/types"
+	"github.com/spf13/viper"
+)
+
+var argentina2025 = []types.Holiday{
+	{Date: "2025-01-01", Type: "inamovible", Name: "Año Nuevo"},
+	{Date: "2025-03-03", Type: "inamovible", Name: "Carnaval"},
+	{Date: "2025-03-04", Type: "inamovible", Name: "Carnaval"},
+	{Date: "2025-05-25", Type: "inamovible", Name: "Día de la Revolución de Mayo"},
+	{Date: "2025-07-09", Type: "inamovible", Name: "Día de la Independencia"},
+	{Date: "2025-08-15", Type: "trasladable", Name: "Paso a la Inmortalidad del General José de San Martín"},
+	{Date: "2025-12-25", Type: "inamovible", Name: "Navidad"},
+}
+
+// newHarness runs the commands at now against argentina2025, with the shipped catalogs
+func newHarness(t *testing.T, now time.Time) *holidaytest.Harness {
+	t.Helper()
+	viper.Set("messages-dir", "../../../messages")
+	t.Cleanup(func() { viper.Set("messages-dir", nil) })
+
+	h := holidaytest.New(now, t.TempDir())
+	t.Cleanup(h.Close)
+	h.Source("AR", holidaytest.Source{ByYear: map[int][]types.Holiday{2025: argentina2025}})
+	return h
+}
+
+func date(month time.Month, day int) time.Time {
+	return time.Date(2025, month, day, 10, 0, 0, 0, time.Local)
+}
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		name    string
+		now     time.Time
+		command string
+		options []holidaytest.Option
+		want    string
+	}{
+		{
+			name:    "next holiday",
+			now:     date(time.July, 1),
+			command: "next-holiday",
+			want:    "🎉 El próximo feriado es **Día de la Independencia** el **Miércoles, 9 de julio**. 🎉",
+		},
+		{
+			name:    "next holiday in english",
+			now:     date(time.July, 1),
+			command: "next-holiday",
+			options: []holidaytest.Option{holidaytest.Locale("en-US")},
+			want:    "The next holiday is **Día de la Independencia**",
+		},
+		{
+			name:    "next holiday today",
+			now:     date(time.July, 9),
+			command: "next-holiday",
+			want:    "¡Hoy es **Día de la Independencia**! 🎉\nAniversario de la declaración de la independencia.",
+		},
+		{
+			name:    "days left",
+			now:     date(time.July, 1),
+			command: "days-left",
+			want:    "🎉 Para el próximo feriado faltan **8** días! [9/7] 🎉\n\n",
+		},
+		{
+			name:    "days left hidden after 31 days",
+			now:     date(time.January, 2),
+			command: "days-left",
+			want:    "🫣 Miralo bajo tu propio riesgo\n||faltan 60 días... el [3/3] ||\n",
+		},
+		{
+			name:    "holidays of month",
+			now:     date(time.January, 2),
+			command: "holidays-of-month",
+			options: []holidaytest.Option{holidaytest.Param("month", 3)},
+			want:    "El mes de **marzo** tiene **2** feriados:\n- Carnaval el **Lunes 3 de Marzo**\n- Carnaval el **Martes 4 de Marzo**\n\nFeriados largos:\n- Desde **Sábado 1 de Marzo** hasta **Martes 4 de Marzo**\n",
+		},
+		{
+			name:    "holidays of month after another option",
+			now:     date(time.January, 2),
+			command: "holidays-of-month",
+			options: []holidaytest.Option{holidaytest.Param("country", "AR"), holidaytest.Param("month", 2)},
+			want:    "No hay feriados en **febrero** 😔",
+		},
+		{
+			name:    "holidays of an empty month",
+			now:     date(time.January, 2),
+			command: "holidays-of-month",
+			options: []holidaytest.Option{holidaytest.Param("month", 2)},
+			want:    "No hay feriados en **febrero** 😔",
+		},
+		{
+			name:    "next large holiday",
+			now:     date(time.July, 1),
+			command: "next-large-holiday",
+			want:    "🎉 El próximo feriado largo es el **Viernes, 15 de agosto** y faltan **45 días!**. 🎉",
+		},
+		{
+			name:    "long weekend of a holiday on a sunday",
+			now:     date(time.May, 1),
+			command: "long-weekends",
+			options: []holidaytest.Option{holidaytest.Param("min-days", 2), holidaytest.Param("year", 2025)},
+			// 25 de Mayo falls on a sunday, so it adds no weekday off
+			want: "En **2025** hay **2** fines de semana largos de 2 días o más:\n" +
+				"- Desde **Sábado 1 de Marzo** hasta **Martes 4 de Marzo** (4 días) · Carnaval · Carnaval\n" +
+				"- Desde **Viernes 15 de Agosto** hasta **Domingo 17 de Agosto** (3 días) · Paso a la Inmortalidad del General José de San Martín\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := newHarness(t, test.now)
+			got, err := h.Reply(holidaytest.Interaction(test.command, test.options...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("got %q\nwant %q", got, test.want)
+			}
+		})
+	}
+}
+
+// TestNextHolidayOfOtherCountry checks the Argentine holiday information is
+// not shown for the holidays of other countries on the same day
+func TestNextHolidayOfOtherCountry(t *testing.T) {
+	h := newHarness(t, date(time.December, 25))
+	h.Source("CL", holidaytest.Source{ByYear: map[int][]types.Holiday{2025: {
+		{Date: "2025-12-25", Type: "inamovible", Name: "Navidad"},
+	}}})
+
+	for _, country := range []string{"AR", "CL"} {
+		t.Run(country, func(t *testing.T) {
+			got, err := h.Reply(holidaytest.Interaction("next-holiday", holidaytest.Param("country", country)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			described := strings.Contains(got, "Celebración cristiana del nacimiento de Jesús.")
+			if described != (country == "AR") {
+				t.Errorf("got %q, want the description only for AR", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package holidays
+
+import (
+	"github.com/FGasquez/alum-bot/internal/holidayinfo"
+	"github.com/FGasquez/alum-bot/internal/messages"
+	"github.com/FGasquez/alum-bot/internal/regions"
+	"github.com/FGasquez/alum-bot/internal/reminders"
+	"github.com/FGasquez/alum-bot/internal/settings"
+	"github.com/FGasquez/alum-bot/internal/types"
+)
+
+// Describe sets the description, history and link of a holiday of the scope
+// country, when it is in the holiday information
+func Describe(scope Scope, holiday types.ParsedHolidays) messages.ContextOption {
+	return func(c *messages.Context) {
+		if info, ok := holidayinfo.For(holiday, scope.country()); ok {
+			describe(c, info)
+		}
+	}
+}
+
+// holidayInfoContext sets the holiday of the holiday-info command
+func holidayInfoContext(info holidayinfo.Info) messages.ContextOption {
+	return func(c *messages.Context) {
+		c.HolidayName = info.Name
+		describe(c, info)
+	}
+}
+
+func describe(c *messages.Context, info holidayinfo.Info) {
+	c.Description = info.Description
+	c.History = info.History
+	c.Link = info.Link
+}
+
+// reminderContext sets the reminder the message is about
+func reminderContext(reminder reminders.Reminder) messages.ContextOption {
+	return func(c *messages.Context) {
+		c.ID = reminder.ID
+		c.Kind = string(reminder.Kind)
+		c.DaysBefore = reminder.DaysBefore
+		c.MinDays = reminder.MinDays
+		c.Date = reminder.Date
+		c.ChannelID = reminder.ChannelID
+	}
+}
+
+func reminderList(list []reminders.Reminder) []messages.Reminder {
+	result := make([]messages.Reminder, len(list))
+	for i, reminder := range list {
+		result[i] = messages.Reminder{
+			ID:         reminder.ID,
+			Kind:       string(reminder.Kind),
+			DaysBefore: reminder.DaysBefore,
+			Date:       reminder.Date,
+			MinDays:    reminder.MinDays,
+			ChannelID:  reminder.ChannelID,
+			Country:    reminder.Country,
+			Region:     reminder.Region,
+		}
+	}
+	return result
+}
+
+func regionList(list []regions.Region) []messages.Region {
+	result := make([]messages.Region, len(list))
+	for i, region := range list {
+		result[i] = messages.Region{Code: region.Code, Name: region.Name, Country: region.Country}
+	}
+	return result
+}
+
+func customHolidayList(list []settings.CustomHoliday) []messages.CustomHoliday {
+	result := make([]messages.CustomHoliday, len(list))
+	for i, holiday := range list {
+		result[i] = messages.CustomHoliday{Date: holiday.Date, Name: holiday.Name}
+	}
+	return result
+}
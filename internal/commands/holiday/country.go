@@ -0,0 +1,111 @@
+package holidays
+
+import (
+	"context"
+
+	"github.com/FGasquez/alum-bot/internal/config"
+	"github.com/FGasquez/alum-bot/internal/helpers"
+	"github.com/FGasquez/alum-bot/internal/logging"
+	"github.com/FGasquez/alum-bot/internal/messages"
+	"github.com/FGasquez/alum-bot/internal/responder"
+	"github.com/FGasquez/alum-bot/internal/settings"
+	"github.com/FGasquez/alum-bot/internal/sources"
+	"github.com/bwmarrin/discordgo"
+)
+
+const CountryCommandName = "country"
+
+var CountryCommand = discordgo.ApplicationCommand{
+	Name:        CountryCommandName,
+	Description: "Manage the country used for the holidays",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "set",
+			Description: "Set your country, or the server country",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         countryOption.Name,
+					Description:  "the country code to use",
+					Required:     true,
+					Autocomplete: true,
+				},
+				guildOption,
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "clear",
+			Description: "Go back to the default country",
+			Options: []*discordgo.ApplicationCommandOption{
+				guildOption,
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "show",
+			Description: "Show your country and the server country",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "list",
+			Description: "List the available countries",
+		},
+	},
+}
+
+func handleCountryCommand(ctx context.Context, r responder.Responder, i *discordgo.InteractionCreate) {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return
+	}
+
+	params := helpers.GetParams(options)
+	guild, _ := params[guildOption.Name].(bool)
+	if guild && !helpers.CanManageGuild(i) {
+		r.ReplyEphemeral(messages.Render(ctx, messages.MessageKeys.MissingPermissions, messages.NewContext(ctx)))
+		return
+	}
+
+	var country string
+	var message string
+	switch options[0].Name {
+	case "set":
+		country = sources.NormalizeCountry(params[countryOption.Name].(string))
+		if _, err := sources.Get(country); err != nil {
+			respondScopeError(ctx, r, errUnknownCountry)
+			return
+		}
+		message = messages.MessageKeys.CountrySet
+	case "clear":
+		message = messages.MessageKeys.CountryCleared
+	case "show":
+		r.ReplyEphemeral(messages.Render(ctx, messages.MessageKeys.CountryShow, messages.NewContext(ctx, func(c *messages.Context) {
+			c.UserCountry = settings.GetUser(helpers.UserID(i)).Country
+			c.GuildCountry = settings.GetGuild(i.GuildID).Country
+			c.DefaultCountry = sources.NormalizeCountry(config.GetDefaultCountry())
+		})))
+		return
+	case "list":
+		r.ReplyEphemeral(messages.Render(ctx, messages.MessageKeys.CountryList, messages.NewContext(ctx, func(c *messages.Context) {
+			c.Countries = sources.Countries()
+		})))
+		return
+	default:
+		return
+	}
+
+	err := updateSettings(i, guild, func(st *settings.Settings) { st.Country = country })
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Failed to save country settings")
+		r.SetEphemeral(true)
+		r.ReplyError(messages.MessageKeys.FailedToSaveSettings)
+		return
+	}
+
+	r.ReplyEphemeral(messages.Render(ctx, message, messages.NewContext(ctx, func(c *messages.Context) {
+		c.Country = country
+		c.Guild = guild
+	})))
+}
@@ -0,0 +1,142 @@
+package holidays
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/FGasquez/alum-bot/internal/helpers"
+	"github.com/FGasquez/alum-bot/internal/logging"
+	"github.com/FGasquez/alum-bot/internal/messages"
+	"github.com/FGasquez/alum-bot/internal/responder"
+	"github.com/FGasquez/alum-bot/internal/settings"
+	"github.com/bwmarrin/discordgo"
+)
+
+const CustomHolidayCommandName = "custom-holiday"
+
+var manageGuildPermission int64 = discordgo.PermissionManageGuild
+
+var customHolidayDateOption = &discordgo.ApplicationCommandOption{
+	Type:        discordgo.ApplicationCommandOptionString,
+	Name:        "date",
+	Description: "yyyy-mm-dd for a single day off, or mm-dd to repeat it every year",
+	Required:    true,
+}
+
+var CustomHolidayCommand = discordgo.ApplicationCommand{
+	Name:                     CustomHolidayCommandName,
+	Description:              "Manage the days off of this server",
+	DefaultMemberPermissions: &manageGuildPermission,
+	Contexts:                 &[]discordgo.InteractionContextType{discordgo.InteractionContextGuild},
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "add",
+			Description: "Add a day off for this server",
+			Options: []*discordgo.ApplicationCommandOption{
+				customHolidayDateOption,
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "name",
+					Description: "name of the day off",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "remove",
+			Description: "Remove a day off of this server",
+			Options: []*discordgo.ApplicationCommandOption{
+				customHolidayDateOption,
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "list",
+			Description: "List the days off of this server",
+		},
+	},
+}
+
+func handleCustomHolidayCommand(ctx context.Context, r responder.Responder, i *discordgo.InteractionCreate) {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 || i.GuildID == "" {
+		return
+	}
+
+	if !helpers.CanManageGuild(i) {
+		r.ReplyEphemeral(messages.Render(ctx, messages.MessageKeys.MissingPermissions, messages.NewContext(ctx)))
+		return
+	}
+
+	params := helpers.GetParams(options)
+	date, _ := params["date"].(string)
+	date = strings.TrimSpace(date)
+	name, _ := params["name"].(string)
+	name = strings.TrimSpace(name)
+	values := messages.NewContext(ctx, func(c *messages.Context) {
+		c.Date = date
+		c.Name = name
+	})
+
+	var message string
+	var err error
+	switch options[0].Name {
+	case "add":
+		if !helpers.ValidHolidayDate(date) {
+			r.ReplyEphemeral(messages.Render(ctx, messages.MessageKeys.InvalidDate, values))
+			return
+		}
+
+		message = messages.MessageKeys.CustomHolidayAdded
+		err = settings.UpdateGuild(i.GuildID, func(st *settings.Settings) {
+			st.CustomHolidays = removeCustomHoliday(st.CustomHolidays, date)
+			st.CustomHolidays = append(st.CustomHolidays, settings.CustomHoliday{Date: date, Name: name})
+			sort.Slice(st.CustomHolidays, func(a, b int) bool {
+				return st.CustomHolidays[a].Date < st.CustomHolidays[b].Date
+			})
+		})
+	case "remove":
+		message = messages.MessageKeys.CustomHolidayNotFound
+		err = settings.UpdateGuild(i.GuildID, func(st *settings.Settings) {
+			remaining := removeCustomHoliday(st.CustomHolidays, date)
+			if len(remaining) != len(st.CustomHolidays) {
+				message = messages.MessageKeys.CustomHolidayRemoved
+			}
+			st.CustomHolidays = remaining
+		})
+	case "list":
+		customHolidays := settings.GetGuild(i.GuildID).CustomHolidays
+		message = messages.MessageKeys.CustomHolidayList
+		if len(customHolidays) == 0 {
+			message = messages.MessageKeys.NoCustomHolidays
+		}
+		r.ReplyEphemeral(messages.Render(ctx, message, messages.NewContext(ctx, func(c *messages.Context) {
+			c.CustomHolidays = customHolidayList(customHolidays)
+		})))
+		return
+	default:
+		return
+	}
+
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Failed to save custom holidays")
+		r.SetEphemeral(true)
+		r.ReplyError(messages.MessageKeys.FailedToSaveSettings)
+		return
+	}
+
+	r.ReplyEphemeral(messages.Render(ctx, message, values))
+}
+
+func removeCustomHoliday(customHolidays []settings.CustomHoliday, date string) []settings.CustomHoliday {
+	remaining := make([]settings.CustomHoliday, 0, len(customHolidays))
+	for _, custom := range customHolidays {
+		if custom.Date != date {
+			remaining = append(remaining, custom)
+		}
+	}
+	return remaining
+}
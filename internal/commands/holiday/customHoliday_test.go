@@ -0,0 +1,125 @@
+package holidays_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FGasquez/alum-bot/internal/commands/holiday/holidaytest"
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestCustomHolidayCommand(t *testing.T) {
+	h := newHarness(t, date(time.July, 1))
+	admin := holidaytest.Permissions(discordgo.PermissionManageGuild)
+
+	steps := []struct {
+		name    string
+		options []holidaytest.Option
+		want    string
+	}{
+		{
+			name:    "empty list",
+			options: []holidaytest.Option{admin, holidaytest.Subcommand("list", nil)},
+			want:    "Este servidor no tiene días no laborables propios",
+		},
+		{
+			name:    "add without permissions",
+			options: []holidaytest.Option{holidaytest.Subcommand("add", map[string]interface{}{"date": "07-02", "name": "Aniversario"})},
+			want:    "🔒 Necesitás el permiso de Gestionar servidor para hacer eso.",
+		},
+		{
+			name:    "add an invalid date",
+			options: []holidaytest.Option{admin, holidaytest.Subcommand("add", map[string]interface{}{"date": "2025-13-01", "name": "Aniversario"})},
+			want:    "❌ La fecha **2025-13-01** no es válida, usá aaaa-mm-dd o mm-dd",
+		},
+		{
+			name:    "add every year",
+			options: []holidaytest.Option{admin, holidaytest.Subcommand("add", map[string]interface{}{"date": " 07-02", "name": "Aniversario "})},
+			want:    "🗓️ Se agregó **Aniversario** el **07-02** como día no laborable",
+		},
+		{
+			name:    "add once",
+			options: []holidaytest.Option{admin, holidaytest.Subcommand("add", map[string]interface{}{"date": "2025-07-01", "name": "Mudanza"})},
+			want:    "🗓️ Se agregó **Mudanza** el **2025-07-01** como día no laborable",
+		},
+		{
+			name:    "rename",
+			options: []holidaytest.Option{admin, holidaytest.Subcommand("add", map[string]interface{}{"date": "07-02", "name": "Fundación"})},
+			want:    "🗓️ Se agregó **Fundación** el **07-02** como día no laborable",
+		},
+		{
+			name:    "list sorted by date",
+			options: []holidaytest.Option{admin, holidaytest.Subcommand("list", nil)},
+			want:    "Días no laborables del servidor:\n- **Fundación** (07-02)\n- **Mudanza** (2025-07-01)",
+		},
+		{
+			name:    "remove a missing one",
+			options: []holidaytest.Option{admin, holidaytest.Subcommand("remove", map[string]interface{}{"date": "07-03"})},
+			want:    "No hay ningún día no laborable el **07-03**",
+		},
+		{
+			name:    "remove",
+			options: []holidaytest.Option{admin, holidaytest.Subcommand("remove", map[string]interface{}{"date": "2025-07-01"})},
+			want:    "🗑️ Se borró el día no laborable del **2025-07-01**",
+		},
+		{
+			name:    "list after removing",
+			options: []holidaytest.Option{admin, holidaytest.Subcommand("list", nil)},
+			want:    "Días no laborables del servidor:\n- **Fundación** (07-02)",
+		},
+	}
+
+	for _, step := range steps {
+		responses, err := h.Run(holidaytest.Interaction("custom-holiday", step.options...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(responses) != 1 {
+			t.Fatalf("%s: got %d responses, want 1", step.name, len(responses))
+		}
+		if got := strings.TrimSpace(responses[0].Content); got != step.want {
+			t.Errorf("%s: got %q\nwant %q", step.name, got, step.want)
+		}
+		if !responses[0].Ephemeral {
+			t.Errorf("%s: the response is public", step.name)
+		}
+	}
+}
+
+// TestCustomHolidaysInListings checks the days off of a guild are merged with
+// the national holidays, only for that guild
+func TestCustomHolidaysInListings(t *testing.T) {
+	h := newHarness(t, date(time.July, 1))
+	admin := holidaytest.Permissions(discordgo.PermissionManageGuild)
+
+	run := func(command string, options ...holidaytest.Option) string {
+		t.Helper()
+		got, err := h.Reply(holidaytest.Interaction(command, options...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	// thursday and friday after the 9th of july make a five days weekend
+	run("custom-holiday", admin, holidaytest.Subcommand("add", map[string]interface{}{"date": "07-10", "name": "Puente"}))
+	run("custom-holiday", admin, holidaytest.Subcommand("add", map[string]interface{}{"date": "2025-07-11", "name": "Aniversario"}))
+
+	if got := run("holidays-of-month", holidaytest.Param("month", 7)); !strings.Contains(got, "Puente") || !strings.Contains(got, "Aniversario") || !strings.Contains(got, "Día de la Independencia") {
+		t.Errorf("holidays of month %q, want the days off with the national holiday", got)
+	}
+	want := "- Desde **Miércoles 9 de Julio** hasta **Domingo 13 de Julio** (5 días)"
+	if got := run("long-weekends", holidaytest.Param("min-days", 5), holidaytest.Param("year", 2025)); !strings.Contains(got, want) {
+		t.Errorf("long weekends %q, want %q", got, want)
+	}
+
+	if got := run("holidays-of-month", holidaytest.Guild("other"), holidaytest.Param("month", 7)); strings.Contains(got, "Puente") {
+		t.Errorf("holidays of month of another guild %q, has the days off", got)
+	}
+
+	run("custom-holiday", admin, holidaytest.Subcommand("remove", map[string]interface{}{"date": "07-10"}))
+	if got := run("holidays-of-month", holidaytest.Param("month", 7)); strings.Contains(got, "Puente") {
+		t.Errorf("holidays of month %q, still has the removed day off", got)
+	}
+}
@@ -1,13 +1,13 @@
 package holidays
 
 import (
-	"time"
+	"context"
 
+	"github.com/FGasquez/alum-bot/internal/clock"
 	"github.com/FGasquez/alum-bot/internal/helpers"
 	"github.com/FGasquez/alum-bot/internal/messages"
-	"github.com/FGasquez/alum-bot/internal/types"
+	"github.com/FGasquez/alum-bot/internal/responder"
 	"github.com/bwmarrin/discordgo"
-	"github.com/sirupsen/logrus"
 )
 
 const DaysLeftToHolidayName = "days-left"
@@ -28,10 +28,13 @@ var HowManyDaysToHoliday = discordgo.ApplicationCommand{
 			Description: "skip weekend in the calculation",
 			Required:    false,
 		},
+		countryOption,
+		regionOption,
+		privateOption,
 	},
 }
 
-var HowManyDaysToHolidayHandlers = func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func handleHowManyDaysToHoliday(ctx context.Context, r responder.Responder, i *discordgo.InteractionCreate) {
 	var skipToday bool = false
 	var skipWeekend bool = true
 
@@ -43,64 +46,28 @@ var HowManyDaysToHolidayHandlers = func(s *discordgo.Session, i *discordgo.Inter
 		skipWeekend = params["skip-weekend"].(bool)
 	}
 
-	daysLeftToHoliday, holiday, isToday := DaysLeft(skipWeekend, skipToday)
-	if daysLeftToHoliday == 0 {
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "Es hoy! 🎉",
-			},
-		})
+	scope, err := ResolveScope(i, params)
+	if err != nil {
+		respondScopeError(ctx, r, err)
 		return
 	}
+	applyPrivacy(r, i, params)
+	deferUnlessCached(r, scope, clock.Now().Year())
 
-	if daysLeftToHoliday == -1 {
-		logrus.Errorf("Failed to parse holiday date")
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: messages.TemplateMessage(messages.GetMessage(messages.MessageKeys.FailedToParseHolidayDate), nil),
-			},
-		})
-		return
-	}
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
 
-	dateFormatted, day, month, _ := helpers.FormatDateToSpanishUnparsed(holiday.Date)
-	parsedDate, err := time.Parse("2006-01-02", holiday.Date)
+	daysLeftToHoliday, holiday, _, err := DaysLeft(ctx, scope, skipWeekend, skipToday)
 	if err != nil {
-		logrus.Errorf("Failed to parse holiday date: %v", err)
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: messages.TemplateMessage(messages.GetMessage(messages.MessageKeys.FailedToParseHolidayDate), nil),
-			},
-		})
+		respondHolidaysError(ctx, r, err)
 		return
 	}
 
-	tmpValues := types.TemplateValues{
-		HolidayName:   holiday.Name,
-		DaysLeft:      daysLeftToHoliday,
-		FormattedDate: dateFormatted,
-		NamedDate: types.NamedDate{
-			Day:   day,
-			Month: month,
-		},
-		RawDate: types.RawDate{
-			Day:   parsedDate.Day(),
-			Month: int(parsedDate.Month()),
-			Year:  parsedDate.Year(),
-		},
-		FullDate:  holiday.Date,
-		Adjacents: holiday.Adjacent,
-		IsToday:   isToday,
+	if daysLeftToHoliday == 0 {
+		r.Reply(messages.Render(ctx, messages.MessageKeys.IsToday, messages.NewContext(ctx, messages.Holiday(holiday, 0), Describe(scope, holiday))))
+		return
 	}
 
-	message := messages.TemplateMessage(messages.GetMessage(messages.MessageKeys.DaysLeft), tmpValues)
-	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Content: message,
-		},
-	})
+	message := messages.Render(ctx, messages.MessageKeys.DaysLeft, messages.NewContext(ctx, messages.Holiday(holiday, daysLeftToHoliday), Describe(scope, holiday)))
+	r.Reply(message)
 }
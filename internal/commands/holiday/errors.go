@@ -0,0 +1,47 @@
+package holidays
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/FGasquez/alum-bot/internal/holidayapi"
+	"github.com/FGasquez/alum-bot/internal/logging"
+	"github.com/FGasquez/alum-bot/internal/messages"
+	"github.com/FGasquez/alum-bot/internal/responder"
+	"github.com/FGasquez/alum-bot/internal/sources"
+)
+
+var errNoUpcomingHolidays = errors.New("no upcoming holidays")
+
+// requestTimeout bounds the holiday lookups of a single interaction, retries included
+const requestTimeout = 10 * time.Second
+
+// holidaysErrorMessage returns the message key that explains a failed holiday lookup to the user
+func holidaysErrorMessage(err error) string {
+	switch {
+	// the interaction deadline may expire while waiting for another lookup of the same holidays
+	case errors.Is(err, holidayapi.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return messages.MessageKeys.APITimeout
+	case errors.Is(err, holidayapi.ErrUnavailable):
+		return messages.MessageKeys.APIUnavailable
+	case errors.Is(err, holidayapi.ErrNotFound), errors.Is(err, errNoUpcomingHolidays):
+		return messages.MessageKeys.HolidaysNotFound
+	default:
+		return messages.MessageKeys.FailedToParseHolidayDate
+	}
+}
+
+// respondHolidaysError tells the user why the holidays could not be retrieved
+func respondHolidaysError(ctx context.Context, r responder.Responder, err error) {
+	logging.FromContext(ctx).WithError(err).Error("Failed to retrieve holidays")
+	r.ReplyError(holidaysErrorMessage(err))
+}
+
+// deferUnlessCached defers the response when the holidays of the scope for the
+// year are not cached, since fetching them may take longer than discord waits
+func deferUnlessCached(r responder.Responder, scope Scope, year int) {
+	if !sources.IsCached(scope.country(), year) {
+		r.Defer()
+	}
+}
@@ -0,0 +1,34 @@
+package holidays
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/FGasquez/alum-bot/internal/holidayapi"
+	"github.com/FGasquez/alum-bot/internal/messages"
+)
+
+func TestHolidaysErrorMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "api timeout", err: &holidayapi.Error{Kind: holidayapi.ErrTimeout}, want: messages.MessageKeys.APITimeout},
+		{name: "deadline waiting for the cache", err: fmt.Errorf("getting holidays: %w", context.DeadlineExceeded), want: messages.MessageKeys.APITimeout},
+		{name: "api unavailable", err: &holidayapi.Error{Kind: holidayapi.ErrUnavailable, StatusCode: 503}, want: messages.MessageKeys.APIUnavailable},
+		{name: "not found", err: &holidayapi.Error{Kind: holidayapi.ErrNotFound}, want: messages.MessageKeys.HolidaysNotFound},
+		{name: "no upcoming holidays", err: errNoUpcomingHolidays, want: messages.MessageKeys.HolidaysNotFound},
+		{name: "other", err: errors.New("boom"), want: messages.MessageKeys.FailedToParseHolidayDate},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := holidaysErrorMessage(test.err); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
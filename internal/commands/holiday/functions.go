@@ -1,19 +1,18 @@
 package holidays
 
 import (
-	"fmt"
-	"io"
-	"net/http"
-	"os"
-	"strconv"
+	"context"
 	"time"
 
+	"github.com/FGasquez/alum-bot/internal/clock"
+	"github.com/FGasquez/alum-bot/internal/sources"
 	"github.com/FGasquez/alum-bot/internal/types"
-	"github.com/sirupsen/logrus"
 )
 
 const (
-	holidaysURL = "https://api.argentinadatos.com/v1/feriados/%s"
+	// DefaultLongWeekendMinDays is the minimum length for a run of days off
+	// to be considered a long weekend when no min-days option is given.
+	DefaultLongWeekendMinDays = 3
 )
 
 type Months int
@@ -33,51 +32,20 @@ const (
 	December  Months = 12
 )
 
-// GetHolidays returns the holidays for the given year
-func GetHolidays(year int, skipPassed bool, adjacents bool, skipWeekends bool, skipToday bool) (types.ProcessedHolidays, error) {
-	cacheFile := fmt.Sprintf("/tmp/holidays_%d.json", year)
-	data := []byte{}
-	// Check if the cache file exists and is not older than 24 hours
-	if fileInfo, err := os.Stat(cacheFile); err == nil {
-		if time.Since(fileInfo.ModTime()) < 24*time.Hour {
-			if data, err = os.ReadFile(cacheFile); err != nil {
-				logrus.Warnf("Failed to read cache file: %v", err)
-			}
-		}
-	}
-
-	if len(data) < 1 {
-		logrus.Info("Cache file not found or is older than 24 hours")
-		url := fmt.Sprintf(holidaysURL, strconv.Itoa(year))
-		logrus.Infof("Getting holidays for year %d", year)
-		logrus.Infof("URL: %s", url)
-		resp, err := http.Get(url)
-
-		logrus.Info("Getting next holiday")
-
-		if err != nil {
-			return types.ProcessedHolidays{}, err
-		}
-		defer resp.Body.Close()
-
-		data, err = io.ReadAll(resp.Body)
-		if err != nil {
-			return types.ProcessedHolidays{}, err
-		}
-
-		// Save the response to the cache file
-		if err := os.WriteFile(cacheFile, data, 0644); err != nil {
-			logrus.Warnf("Failed to write cache file: %v", err)
-		}
-
+// GetHolidays returns the holidays for the given year, including the ones of the scope
+func GetHolidays(ctx context.Context, scope Scope, year int, skipPassed bool, adjacents bool, skipWeekends bool, skipToday bool) (types.ProcessedHolidays, error) {
+	provider, err := sources.Get(scope.country())
+	if err != nil {
+		return types.ProcessedHolidays{}, err
 	}
 
-	processedHolidays, err := HolidaysProcessor(data, skipPassed, adjacents, skipWeekends, skipToday)
+	rawHolidays, err := provider.Holidays(ctx, year)
 	if err != nil {
 		return types.ProcessedHolidays{}, err
 	}
+	rawHolidays = mergeHolidays(rawHolidays, scope.holidays(year))
 
-	return processedHolidays, nil
+	return ProcessHolidays(rawHolidays, skipPassed, adjacents, skipWeekends, skipToday), nil
 }
 
 // IsHoliday returns true if the given date is a holiday
@@ -94,46 +62,69 @@ func IsHoliday(date time.Time, holidays []types.Holiday) bool {
 	return false
 }
 
-func isToday(parsedDate time.Time) bool {
-	y1, m1, d1 := time.Now().Date()
-	y2, m2, d2 := parsedDate.Date()
-	return y1 == y2 && m1 == m2 && d1 == d2
-}
-
 // NextHoliday returns the next holiday
-func NextHoliday(date time.Time, skipWeekends bool, skipToday bool) (*types.ParsedHolidays, bool) {
-	logrus.Info(" ***** Getting next holiday")
-	holidays, err := GetHolidays(date.Year(), false, false, skipWeekends, skipToday)
+func NextHoliday(ctx context.Context, scope Scope, date time.Time, skipWeekends bool, skipToday bool) (*types.ParsedHolidays, bool) {
+	index, err := GetIndex(ctx, scope, date.Year())
 	if err != nil {
 		return nil, false
 	}
 
-	return &holidays.Next, holidays.Next.IsToday
+	next, ok := index.Next(date, skipWeekends, skipToday)
+	if !ok {
+		return nil, false
+	}
+
+	return &next, next.IsToday
+}
+
+// Calculate how many days are left for the giving holiday. When there are no
+// holidays left this year, the first one of the next year is used.
+func DaysLeft(ctx context.Context, scope Scope, skipWeekends bool, skipToday bool) (int, types.ParsedHolidays, bool, error) {
+	now := clock.Now()
+	for _, year := range []int{now.Year(), now.Year() + 1} {
+		index, err := GetIndex(ctx, scope, year)
+		if err != nil {
+			return 0, types.ParsedHolidays{}, false, err
+		}
+
+		if next, ok := index.Next(now, skipWeekends, skipToday); ok {
+			return next.DaysLeftToHoliday, next, next.IsToday, nil
+		}
+	}
+
+	return 0, types.ParsedHolidays{}, false, errNoUpcomingHolidays
 }
 
-// Calculate how many days are left for the giving holiday
-func DaysLeft(skipWeekends bool, skipToday bool) (int, types.ParsedHolidays, bool) {
-	holidays, err := GetHolidays(time.Now().Year(), true, false, skipWeekends, skipToday)
+func GetAllHolidaysOfMonth(ctx context.Context, scope Scope, month Months, year int) ([]types.ParsedHolidays, error) {
+	index, err := GetIndex(ctx, scope, year)
 	if err != nil {
-		return 0, types.ParsedHolidays{}, false
+		return nil, err
 	}
 
-	return holidays.Next.DaysLeftToHoliday, holidays.Next, holidays.Next.IsToday
+	return index.Month(month, clock.Now()), nil
 }
 
-func GetAllHolidaysOfMonth(month Months, year int) ([]types.ParsedHolidays, error) {
-	holidays, err := GetHolidays(year, false, true, false, false)
+// GetLongWeekends returns the long weekends of the given year lasting at least minDays days
+func GetLongWeekends(ctx context.Context, scope Scope, year int, minDays int) ([]types.LongWeekend, error) {
+	index, err := GetIndex(ctx, scope, year)
 	if err != nil {
 		return nil, err
 	}
 
-	var holidaysOfMonth []types.ParsedHolidays
-	for _, holiday := range holidays.All {
+	return index.LongWeekends(minDays, clock.Now()), nil
+}
 
-		if Months(holiday.RawDate.Month) == month {
-			holidaysOfMonth = append(holidaysOfMonth, holiday)
+// LongWeekendsOfMonth returns the long weekends with at least one day in the given month
+func LongWeekendsOfMonth(longWeekends []types.LongWeekend, month Months) []types.LongWeekend {
+	var longWeekendsOfMonth []types.LongWeekend
+	for _, longWeekend := range longWeekends {
+		for _, day := range longWeekend.Days {
+			if Months(day.RawDate.Month) == month {
+				longWeekendsOfMonth = append(longWeekendsOfMonth, longWeekend)
+				break
+			}
 		}
 	}
 
-	return holidaysOfMonth, nil
+	return longWeekendsOfMonth
 }
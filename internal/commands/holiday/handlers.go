@@ -0,0 +1,42 @@
+package holidays
+
+import (
+	"context"
+
+	"github.com/FGasquez/alum-bot/internal/logging"
+	"github.com/FGasquez/alum-bot/internal/messages"
+	"github.com/FGasquez/alum-bot/internal/responder"
+	"github.com/bwmarrin/discordgo"
+)
+
+// Handler answers a command interaction through a responder
+type Handler func(ctx context.Context, r responder.Responder, i *discordgo.InteractionCreate)
+
+// Handlers are the command handlers by command name, they do not need a
+// discord session so they can be run with a responder.Recorder
+var Handlers = map[string]Handler{
+	HolidaysCommandName:      handleHolidaysCommand,
+	DaysLeftToHolidayName:    handleHowManyDaysToHoliday,
+	HolidaysOfMonthName:      handleHolidaysOfMonth,
+	HolidaysLargeCommandName: handleHolidayLargeCommand,
+	LongWeekendsCommandName:  handleLongWeekendsCommand,
+	RegionCommandName:        handleRegionCommand,
+	CustomHolidayCommandName: handleCustomHolidayCommand,
+	CountryCommandName:       handleCountryCommand,
+	PrivacyCommandName:       handlePrivacyCommand,
+	RemindCommandName:        handleRemindCommand,
+	MessagesCommandName:      handleMessagesCommand,
+	HolidayInfoCommandName:   handleHolidayInfoCommand,
+}
+
+// ModalHandlers are the handlers of modal submits, by the custom ID of the
+// modal up to its first colon
+var ModalHandlers = map[string]Handler{
+	MessagesCommandName: handleMessagesModal,
+}
+
+// InteractionContext returns the context every handler runs with, carrying
+// the logger and the messages scope of the interaction
+func InteractionContext(i *discordgo.InteractionCreate) context.Context {
+	return messages.WithScope(logging.Interaction(context.Background(), i), messages.ScopeOf(i.Interaction))
+}
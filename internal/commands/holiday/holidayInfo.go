@@ -0,0 +1,46 @@
+package holidays
+
+import (
+	"context"
+
+	"github.com/FGasquez/alum-bot/internal/helpers"
+	"github.com/FGasquez/alum-bot/internal/holidayinfo"
+	"github.com/FGasquez/alum-bot/internal/messages"
+	"github.com/FGasquez/alum-bot/internal/responder"
+	"github.com/bwmarrin/discordgo"
+)
+
+const HolidayInfoCommandName = "holiday-info"
+
+var holidayNameOption = &discordgo.ApplicationCommandOption{
+	Type:         discordgo.ApplicationCommandOptionString,
+	Name:         "name",
+	Description:  "the holiday, e.g. Día de la Independencia",
+	Required:     true,
+	Autocomplete: true,
+}
+
+var HolidayInfoCommand = discordgo.ApplicationCommand{
+	Name:        HolidayInfoCommandName,
+	Description: "Get the description and history of a holiday",
+	Options: []*discordgo.ApplicationCommandOption{
+		holidayNameOption,
+		privateOption,
+	},
+}
+
+func handleHolidayInfoCommand(ctx context.Context, r responder.Responder, i *discordgo.InteractionCreate) {
+	params := helpers.GetParams(i.ApplicationCommandData().Options)
+	name, _ := params[holidayNameOption.Name].(string)
+
+	info, ok := holidayinfo.Lookup(name)
+	if !ok {
+		r.ReplyEphemeral(messages.Render(ctx, messages.MessageKeys.UnknownHoliday, messages.NewContext(ctx, func(c *messages.Context) {
+			c.Name = name
+		})))
+		return
+	}
+
+	applyPrivacy(r, i, params)
+	r.Reply(messages.Render(ctx, messages.MessageKeys.HolidayInfo, messages.NewContext(ctx, holidayInfoContext(info))))
+}
@@ -1,13 +1,15 @@
 package holidays
 
 import (
-	"time"
+	"context"
 
+	"github.com/FGasquez/alum-bot/internal/clock"
 	"github.com/FGasquez/alum-bot/internal/helpers"
+	"github.com/FGasquez/alum-bot/internal/logging"
 	"github.com/FGasquez/alum-bot/internal/messages"
+	"github.com/FGasquez/alum-bot/internal/responder"
 	"github.com/FGasquez/alum-bot/internal/types"
 	"github.com/bwmarrin/discordgo"
-	"github.com/sirupsen/logrus"
 )
 
 const HolidaysOfMonthName = "holidays-of-month"
@@ -72,82 +74,62 @@ var HolidaysOfMonth = discordgo.ApplicationCommand{
 				},
 			},
 		},
+		countryOption,
+		regionOption,
+		privateOption,
 	},
 }
 
-var HolidaysOfMonthHandlers = func(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	month := i.ApplicationCommandData().Options[0].IntValue()
+func handleHolidaysOfMonth(ctx context.Context, r responder.Responder, i *discordgo.InteractionCreate) {
+	// discord sends the options in the order they were typed, not the declared one
+	params := helpers.GetParams(i.ApplicationCommandData().Options)
+	month := int64(helpers.IntParam(params["month"]))
 	monthName := helpers.MonthsToSpanish(month)
-	year := time.Now().Year()
+	year := clock.Now().Year()
 
 	// TODO: Fix year param
-	params := helpers.GetParams(i.ApplicationCommandData().Options)
 	if _, ok := params["year"]; ok {
 		year = int(params["year"].(int))
 	}
 
-	holidaysOfMonth, err := GetAllHolidaysOfMonth(Months(month), year)
+	scope, err := ResolveScope(i, params)
 	if err != nil {
-		logrus.Errorf("Failed to retrieve holidays of the month: %v", err)
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: messages.TemplateMessage(messages.GetMessage(messages.MessageKeys.FailedToParseHolidayDate), nil),
-			},
-		})
+		respondScopeError(ctx, r, err)
 		return
 	}
+	applyPrivacy(r, i, params)
+	deferUnlessCached(r, scope, year)
 
-	if len(holidaysOfMonth) == 0 {
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: messages.TemplateMessage(messages.GetMessage(messages.MessageKeys.NoHolidaysOfMonth), map[string]interface{}{
-					"Month": monthName,
-				}),
-			},
-		})
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	holidaysOfMonth, err := GetAllHolidaysOfMonth(ctx, scope, Months(month), year)
+	if err != nil {
+		respondHolidaysError(ctx, r, err)
 		return
 	}
 
-	var adjacentHolidays [][]types.ParsedHolidays
-	adjacentMap := make(map[string]bool)
-
-	for _, holiday := range holidaysOfMonth {
-		var currentAdjacents []types.ParsedHolidays
-
-		for _, adjacent := range holiday.Adjacent {
-			if adjacentMap[adjacent.Date] {
-				continue
-			}
-
-			currentAdjacents = append(currentAdjacents, adjacent)
-			adjacentMap[adjacent.Date] = true
-		}
+	if len(holidaysOfMonth) == 0 {
+		r.Reply(messages.Render(ctx, messages.MessageKeys.NoHolidaysOfMonth, messages.NewContext(ctx, messages.Month(monthName, nil))))
+		return
+	}
 
-		if len(currentAdjacents) > 1 { // <<-- Important: at least 2 to consider it a long holiday
-			adjacentHolidays = append(adjacentHolidays, currentAdjacents)
-		}
+	longWeekends, err := GetLongWeekends(ctx, scope, year, DefaultLongWeekendMinDays)
+	if err != nil {
+		logging.FromContext(ctx).Errorf("Failed to retrieve long weekends: %v", err)
 	}
+
+	longWeekendsOfMonth := LongWeekendsOfMonth(longWeekends, Months(month))
 	holidaysOfMonthFiltered := make([]types.ParsedHolidays, 0, len(holidaysOfMonth))
 	for _, holiday := range holidaysOfMonth {
 		if holiday.Type != types.Weekend {
 			holidaysOfMonthFiltered = append(holidaysOfMonthFiltered, holiday)
 		}
 	}
-	tmpValues := types.MonthTemplateValues{
-		Month:        monthName,
-		HolidaysList: holidaysOfMonthFiltered,
-		Adjacents:    adjacentHolidays,
-		Count:        len(holidaysOfMonthFiltered),
-	}
+	values := messages.NewContext(ctx, messages.Month(monthName, holidaysOfMonthFiltered))
+	values.LongWeekends = longWeekendsOfMonth
 
-	message := messages.TemplateMessage(messages.GetMessage(messages.MessageKeys.HolidaysOfMonth), tmpValues)
+	message := messages.Render(ctx, messages.MessageKeys.HolidaysOfMonth, values)
 
-	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Content: message,
-		},
-	})
+	r.Reply(message)
 }
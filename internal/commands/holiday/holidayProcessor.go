@@ -6,6 +6,7 @@ import (
 	"sort"
 	"time"
 
+	"github.com/FGasquez/alum-bot/internal/clock"
 	"github.com/FGasquez/alum-bot/internal/types"
 )
 
@@ -18,10 +19,16 @@ func HolidaysProcessor(jsonData []byte, skipPassed, adjacents, skipWeekends, ski
 		return types.ProcessedHolidays{}, err
 	}
 
-	now := time.Now()
-	// Use the start of today for consistent date comparisons.
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return ProcessHolidays(rawHolidays, skipPassed, adjacents, skipWeekends, skipToday), nil
+}
+
+// ProcessHolidays applies filters to already decoded holidays and identifies relationships.
+func ProcessHolidays(rawHolidays []types.Holiday, skipPassed, adjacents, skipWeekends, skipToday bool) types.ProcessedHolidays {
+	return processHolidaysAt(rawHolidays, startOfDay(clock.Now()), skipPassed, adjacents, skipWeekends, skipToday)
+}
 
+// processHolidaysAt is ProcessHolidays relative to the given day instead of today.
+func processHolidaysAt(rawHolidays []types.Holiday, today time.Time, skipPassed, adjacents, skipWeekends, skipToday bool) types.ProcessedHolidays {
 	// Pre-allocate slice capacity to prevent reallocations, improving performance.
 	parsedHolidays := make([]types.ParsedHolidays, 0, len(rawHolidays))
 
@@ -38,7 +45,7 @@ func HolidaysProcessor(jsonData []byte, skipPassed, adjacents, skipWeekends, ski
 		if skipToday && isHolidayToday {
 			continue
 		}
-		if skipPassed && date.Before(today) && !isToday(date) {
+		if skipPassed && date.Before(today) {
 			continue
 		}
 		if skipWeekends && isWeekend(date) {
@@ -55,7 +62,7 @@ func HolidaysProcessor(jsonData []byte, skipPassed, adjacents, skipWeekends, ski
 			RawDate:           types.RawDate{Year: date.Year(), Month: int(date.Month()), Day: date.Day()},
 			FullDate:          date.Format(time.RFC3339),
 			IsToday:           isHolidayToday,
-			DaysLeftToHoliday: int(math.Ceil(date.Sub(today).Hours() / 24)),
+			DaysLeftToHoliday: daysUntil(date, today),
 		})
 	}
 
@@ -64,28 +71,59 @@ func HolidaysProcessor(jsonData []byte, skipPassed, adjacents, skipWeekends, ski
 	})
 
 	// Group adjacent holidays
+	var longWeekends []types.LongWeekend
 	if adjacents {
-		parsedHolidays = groupAdjacentHolidays(parsedHolidays)
+		parsedHolidays, longWeekends = groupAdjacentHolidays(parsedHolidays, today)
 	}
 
 	next, previous := findNextAndPrevious(parsedHolidays, today)
 
 	return types.ProcessedHolidays{
-		Next:     next,
-		Previous: previous,
-		All:      parsedHolidays,
-	}, nil
+		Next:         next,
+		Previous:     previous,
+		All:          parsedHolidays,
+		LongWeekends: longWeekends,
+	}
+}
+
+// mergeHolidays layers extra holidays on top of base ones. Dates already
+// present in base are kept as they are.
+func mergeHolidays(base []types.Holiday, extra []types.Holiday) []types.Holiday {
+	if len(extra) == 0 {
+		return base
+	}
+
+	dates := make(map[string]bool, len(base))
+	for _, h := range base {
+		dates[h.Date] = true
+	}
+
+	merged := append([]types.Holiday{}, base...)
+	for _, h := range extra {
+		if dates[h.Date] {
+			continue
+		}
+		dates[h.Date] = true
+		merged = append(merged, h)
+	}
+
+	return merged
 }
 
-func groupAdjacentHolidays(sortedHolidays []types.ParsedHolidays) []types.ParsedHolidays {
+// groupAdjacentHolidays links holidays that form a continuous run of days off
+// together with the weekends around them, and returns every run longer than a
+// single day as a long weekend.
+func groupAdjacentHolidays(sortedHolidays []types.ParsedHolidays, today time.Time) ([]types.ParsedHolidays, []types.LongWeekend) {
 	if len(sortedHolidays) == 0 {
-		return sortedHolidays
+		return sortedHolidays, nil
 	}
 
 	var allGroupedHolidays []types.ParsedHolidays
+	var longWeekends []types.LongWeekend
 	var holidayGroups [][]*types.ParsedHolidays
 
-	// Find groups of holidays that are directly adjacent (e.g., Mon, Tue).
+	// Find groups of holidays that are adjacent (e.g., Mon, Tue), or only
+	// separated by a weekend (e.g., Fri, Mon).
 	for i := 0; i < len(sortedHolidays); {
 		group := []*types.ParsedHolidays{&sortedHolidays[i]}
 		j := i
@@ -93,9 +131,8 @@ func groupAdjacentHolidays(sortedHolidays []types.ParsedHolidays) []types.Parsed
 			prevDate, _ := time.ParseInLocation(dateLayout, sortedHolidays[j].Date, time.Local)
 			nextDate, _ := time.ParseInLocation(dateLayout, sortedHolidays[j+1].Date, time.Local)
 
-			// Check if the next holiday is exactly one day after the current one.
-			if !prevDate.AddDate(0, 0, 1).Equal(nextDate) {
-				break // Not directly adjacent.
+			if !onlyWeekendsBetween(prevDate, nextDate) {
+				break // Not adjacent.
 			}
 			group = append(group, &sortedHolidays[j+1])
 			j++
@@ -127,16 +164,102 @@ func groupAdjacentHolidays(sortedHolidays []types.ParsedHolidays) []types.Parsed
 
 		finalGroup = append(finalGroup, findSucceedingWeekends(lastHolidayDate)...)
 
-		// Link all items in the final group together.
-		if len(finalGroup) > 1 {
-			for i := range finalGroup {
-				finalGroup[i].Adjacent = finalGroup
+		for i := range finalGroup {
+			if finalGroup[i].Type == types.Weekend {
+				date, _ := time.ParseInLocation(dateLayout, finalGroup[i].Date, time.Local)
+				finalGroup[i].DaysLeftToHoliday = daysUntil(date, today)
+			}
+		}
+
+		// A holiday on a weekend next to the other weekend day is not a long
+		// weekend, the run must add at least one weekday off.
+		if len(finalGroup) < 2 || !addsWeekdayOff(finalGroup) {
+			for _, holiday := range group {
+				allGroupedHolidays = append(allGroupedHolidays, *holiday)
 			}
+			continue
+		}
+
+		// Link all items in the final group together.
+		for i := range finalGroup {
+			finalGroup[i].Adjacent = finalGroup
 		}
+		longWeekends = append(longWeekends, newLongWeekend(finalGroup))
 		allGroupedHolidays = append(allGroupedHolidays, finalGroup...)
 	}
 
-	return allGroupedHolidays
+	return allGroupedHolidays, longWeekends
+}
+
+// addsWeekdayOff reports whether a run of days off has a holiday on a weekday
+func addsWeekdayOff(days []types.ParsedHolidays) bool {
+	for _, day := range days {
+		if day.Type == types.Weekend {
+			continue
+		}
+		date, err := time.ParseInLocation(dateLayout, day.Date, time.Local)
+		if err == nil && !isWeekend(date) {
+			return true
+		}
+	}
+	return false
+}
+
+// newLongWeekend builds a long weekend from a linked group of days off.
+func newLongWeekend(days []types.ParsedHolidays) types.LongWeekend {
+	longWeekend := types.LongWeekend{
+		Start:    days[0],
+		End:      days[len(days)-1],
+		Length:   len(days),
+		Days:     days,
+		DaysLeft: days[0].DaysLeftToHoliday,
+	}
+
+	for _, day := range days {
+		switch day.Type {
+		case types.Weekend:
+		case types.Bridge:
+			longWeekend.BridgeDays = append(longWeekend.BridgeDays, day)
+		default:
+			longWeekend.Holidays = append(longWeekend.Holidays, day)
+		}
+	}
+
+	return longWeekend
+}
+
+// FilterLongWeekends returns the long weekends that last at least minDays days.
+func FilterLongWeekends(longWeekends []types.LongWeekend, minDays int) []types.LongWeekend {
+	filtered := make([]types.LongWeekend, 0, len(longWeekends))
+	for _, longWeekend := range longWeekends {
+		if longWeekend.Length >= minDays {
+			filtered = append(filtered, longWeekend)
+		}
+	}
+	return filtered
+}
+
+// onlyWeekendsBetween reports whether every day strictly between start and end
+// is a weekend day. Consecutive days trivially satisfy it.
+func onlyWeekendsBetween(start, end time.Time) bool {
+	if !start.Before(end) {
+		return false
+	}
+	for d := start.AddDate(0, 0, 1); d.Before(end); d = d.AddDate(0, 0, 1) {
+		if !isWeekend(d) {
+			return false
+		}
+	}
+	return true
+}
+
+// startOfDay is used for consistent date comparisons.
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func daysUntil(date, today time.Time) int {
+	return int(math.Ceil(date.Sub(today).Hours() / 24))
 }
 
 // findPrecedingWeekends finds all weekend days immediately before a given date.
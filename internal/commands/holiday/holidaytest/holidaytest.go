@@ -0,0 +1,281 @@
+// Package holidaytest runs the holiday command handlers without discord: it
+// builds synthetic interactions, serves holidays from memory, fixes the clock
+// and records the rendered responses.
+package holidaytest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/FGasquez/alum-bot/internal/clock"
+	holidays "github.com/FGasquez/alum-bot/internal/commands/holiday"
+	"github.com/FGasquez/alum-bot/internal/holidayapi"
+	"github.com/FGasquez/alum-bot/internal/reminders"
+	"github.com/FGasquez/alum-bot/internal/responder"
+	"github.com/FGasquez/alum-bot/internal/settings"
+	"github.com/FGasquez/alum-bot/internal/sources"
+	"github.com/FGasquez/alum-bot/internal/types"
+	"github.com/bwmarrin/discordgo"
+	"github.com/spf13/viper"
+)
+
+// Option changes a synthetic interaction
+type Option func(i *discordgo.InteractionCreate)
+
+// Interaction builds the interaction of a slash command. It is sent from a
+// guild by a member unless changed with the options.
+func Interaction(command string, options ...Option) *discordgo.InteractionCreate {
+	i := &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			ID:      "interaction",
+			AppID:   "application",
+			Type:    discordgo.InteractionApplicationCommand,
+			GuildID: "guild",
+			Member: &discordgo.Member{
+				User: &discordgo.User{ID: "user"},
+			},
+			Locale: discordgo.SpanishES,
+			Token:  "token",
+			Data: discordgo.ApplicationCommandInteractionData{
+				ID:   "command",
+				Name: command,
+			},
+		},
+	}
+
+	for _, option := range options {
+		option(i)
+	}
+	return i
+}
+
+// ModalSubmit builds the submit of a modal with the values of its text
+// inputs by custom ID. The command options, Param and Subcommand, do not
+// apply to it.
+func ModalSubmit(customID string, values map[string]string, options ...Option) *discordgo.InteractionCreate {
+	i := Interaction("", options...)
+	i.Type = discordgo.InteractionModalSubmit
+
+	ids := make([]string, 0, len(values))
+	for id := range values {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	data := discordgo.ModalSubmitInteractionData{CustomID: customID}
+	for _, id := range ids {
+		data.Components = append(data.Components, &discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				&discordgo.TextInput{CustomID: id, Value: values[id]},
+			},
+		})
+	}
+	i.Data = data
+	return i
+}
+
+// Param adds a command option, its type is taken from the value
+func Param(name string, value interface{}) Option {
+	return func(i *discordgo.InteractionCreate) {
+		data := i.ApplicationCommandData()
+		data.Options = append(data.Options, param(name, value))
+		i.Data = data
+	}
+}
+
+// Subcommand adds a subcommand with its own options
+func Subcommand(name string, params map[string]interface{}) Option {
+	return func(i *discordgo.InteractionCreate) {
+		names := make([]string, 0, len(params))
+		for n := range params {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+
+		subcommand := &discordgo.ApplicationCommandInteractionDataOption{
+			Name: name,
+			Type: discordgo.ApplicationCommandOptionSubCommand,
+		}
+		for _, n := range names {
+			subcommand.Options = append(subcommand.Options, param(n, params[n]))
+		}
+
+		data := i.ApplicationCommandData()
+		data.Options = append(data.Options, subcommand)
+		i.Data = data
+	}
+}
+
+// Guild sends the interaction from the given guild, an empty ID sends it from a DM
+func Guild(guildID string) Option {
+	return func(i *discordgo.InteractionCreate) {
+		i.GuildID = guildID
+		if guildID == "" && i.Member != nil {
+			i.User = i.Member.User
+			i.Member = nil
+		}
+	}
+}
+
+// User sends the interaction as the given user
+func User(userID string) Option {
+	return func(i *discordgo.InteractionCreate) {
+		if i.Member != nil {
+			i.Member.User = &discordgo.User{ID: userID}
+			return
+		}
+		i.User = &discordgo.User{ID: userID}
+	}
+}
+
+// Channel sends the interaction from the given channel
+func Channel(channelID string) Option {
+	return func(i *discordgo.InteractionCreate) {
+		i.ChannelID = channelID
+	}
+}
+
+// Permissions sets the permissions of the member sending the interaction
+func Permissions(permissions int64) Option {
+	return func(i *discordgo.InteractionCreate) {
+		if i.Member != nil {
+			i.Member.Permissions = permissions
+		}
+	}
+}
+
+// Locale sets the locale of the user sending the interaction
+func Locale(locale discordgo.Locale) Option {
+	return func(i *discordgo.InteractionCreate) {
+		i.Locale = locale
+	}
+}
+
+// param builds an option the way discord decodes it, numbers come as float64
+func param(name string, value interface{}) *discordgo.ApplicationCommandInteractionDataOption {
+	option := &discordgo.ApplicationCommandInteractionDataOption{Name: name, Value: value}
+	switch v := value.(type) {
+	case bool:
+		option.Type = discordgo.ApplicationCommandOptionBoolean
+	case string:
+		option.Type = discordgo.ApplicationCommandOptionString
+	case int:
+		option.Type = discordgo.ApplicationCommandOptionInteger
+		option.Value = float64(v)
+	case int64:
+		option.Type = discordgo.ApplicationCommandOptionInteger
+		option.Value = float64(v)
+	case float64:
+		option.Type = discordgo.ApplicationCommandOptionNumber
+	default:
+		panic(fmt.Sprintf("holidaytest: unsupported option value %T", value))
+	}
+	return option
+}
+
+// Source is a holiday source serving holidays from memory
+type Source struct {
+	ByYear map[int][]types.Holiday
+	// Err is returned instead of the holidays when set
+	Err error
+}
+
+func (s Source) Holidays(ctx context.Context, year int) ([]types.Holiday, error) {
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	holidays, ok := s.ByYear[year]
+	if !ok {
+		return nil, holidayapi.ErrNotFound
+	}
+	return holidays, nil
+}
+
+// IsCached reports every year as cached, so handlers never defer
+func (s Source) IsCached(year int) bool {
+	return true
+}
+
+// Harness runs the command handlers against a fixed clock and in-memory sources
+type Harness struct {
+	// restore undoes the registrations of Source, in reverse order on Close
+	restore []func()
+}
+
+// New fixes the clock at now and keeps the settings in dataDir. The caller
+// must call Close to restore the system clock and the sources.
+func New(now time.Time, dataDir string) *Harness {
+	clock.Set(clock.Fixed(now))
+	viper.Set("data-dir", dataDir)
+	settings.Reset()
+	reminders.Reset()
+	return &Harness{}
+}
+
+// Source registers the holidays of a country until Close
+func (h *Harness) Source(country string, source Source) {
+	previous, err := sources.Get(country)
+	h.restore = append(h.restore, func() {
+		if err != nil {
+			sources.Unregister(country)
+			return
+		}
+		sources.Register(country, previous)
+	})
+	sources.Register(country, source)
+}
+
+// Run dispatches the interaction to the handler of its command or modal
+func (h *Harness) Run(i *discordgo.InteractionCreate) ([]responder.Response, error) {
+	var handler holidays.Handler
+	var ok bool
+	if i.Type == discordgo.InteractionModalSubmit {
+		name, _, _ := strings.Cut(i.ModalSubmitData().CustomID, ":")
+		handler, ok = holidays.ModalHandlers[name]
+	} else {
+		handler, ok = holidays.Handlers[i.ApplicationCommandData().Name]
+	}
+	if !ok {
+		return nil, fmt.Errorf("no handler for %s", name(i))
+	}
+
+	ctx := holidays.InteractionContext(i)
+	recorder := responder.NewRecorder(ctx, i)
+	handler(ctx, recorder, i)
+	return recorder.Responses(), nil
+}
+
+// name describes the command or modal of an interaction for errors
+func name(i *discordgo.InteractionCreate) string {
+	if i.Type == discordgo.InteractionModalSubmit {
+		return fmt.Sprintf("modal %q", i.ModalSubmitData().CustomID)
+	}
+	return fmt.Sprintf("command %q", i.ApplicationCommandData().Name)
+}
+
+// Reply runs the interaction and returns the content of its last response
+func (h *Harness) Reply(i *discordgo.InteractionCreate) (string, error) {
+	responses, err := h.Run(i)
+	if err != nil {
+		return "", err
+	}
+	if len(responses) == 0 {
+		return "", fmt.Errorf("%s did not respond", name(i))
+	}
+	return responses[len(responses)-1].Content, nil
+}
+
+// Close restores the system clock and the sources replaced by Source, and
+// forgets the settings
+func (h *Harness) Close() {
+	for n := len(h.restore) - 1; n >= 0; n-- {
+		h.restore[n]()
+	}
+	h.restore = nil
+	clock.Set(nil)
+	settings.Reset()
+	reminders.Reset()
+}
@@ -0,0 +1,32 @@
+package holidaytest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FGasquez/alum-bot/internal/commands/holiday/holidaytest"
+	"github.com/FGasquez/alum-bot/internal/sources"
+)
+
+func TestCloseRestoresSources(t *testing.T) {
+	previous, err := sources.Get("AR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sources.Get("ZZ"); err == nil {
+		t.Fatal("ZZ has a source before the test")
+	}
+
+	h := holidaytest.New(time.Date(2025, time.July, 1, 10, 0, 0, 0, time.Local), t.TempDir())
+	h.Source("AR", holidaytest.Source{})
+	h.Source("AR", holidaytest.Source{})
+	h.Source("zz", holidaytest.Source{})
+	h.Close()
+
+	if provider, err := sources.Get("AR"); err != nil || provider != previous {
+		t.Errorf("AR has %v, %v, want the previous source", provider, err)
+	}
+	if provider, err := sources.Get("ZZ"); err == nil {
+		t.Errorf("ZZ still has %v", provider)
+	}
+}
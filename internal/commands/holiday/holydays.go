@@ -1,13 +1,13 @@
 package holidays
 
 import (
-	"time"
+	"context"
 
+	"github.com/FGasquez/alum-bot/internal/clock"
 	"github.com/FGasquez/alum-bot/internal/helpers"
 	"github.com/FGasquez/alum-bot/internal/messages"
-	"github.com/FGasquez/alum-bot/internal/types"
+	"github.com/FGasquez/alum-bot/internal/responder"
 	"github.com/bwmarrin/discordgo"
-	"github.com/sirupsen/logrus"
 )
 
 const HolidaysCommandName = "next-holiday"
@@ -28,10 +28,13 @@ var HolidaysCommands = discordgo.ApplicationCommand{
 			Description: "skip weekend in the calculation",
 			Required:    false,
 		},
+		countryOption,
+		regionOption,
+		privateOption,
 	},
 }
 
-var HolidaysCommandHandlers = func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func handleHolidaysCommand(ctx context.Context, r responder.Responder, i *discordgo.InteractionCreate) {
 	var skipToday bool = false
 	var skipWeekend bool = true
 
@@ -43,55 +46,28 @@ var HolidaysCommandHandlers = func(s *discordgo.Session, i *discordgo.Interactio
 		skipWeekend = params["skip-weekend"].(bool)
 	}
 
-	daysLeftToHoliday, nextHoliday, isToday := DaysLeft(skipWeekend, skipToday)
-
-	if isToday {
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "Es hoy! 🎉",
-			},
-		})
+	scope, err := ResolveScope(i, params)
+	if err != nil {
+		respondScopeError(ctx, r, err)
 		return
 	}
+	applyPrivacy(r, i, params)
+	deferUnlessCached(r, scope, clock.Now().Year())
 
-	// Parse nextHoliday.Date into a time.Time object
-	parsedDate, err := time.Parse("2006-01-02", nextHoliday.Date)
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	daysLeftToHoliday, nextHoliday, isToday, err := DaysLeft(ctx, scope, skipWeekend, skipToday)
 	if err != nil {
-		logrus.Errorf("Failed to parse holiday date: %v", err)
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "❌ Failed to retrieve the next holiday. Please try again later.",
-			},
-		})
+		respondHolidaysError(ctx, r, err)
 		return
 	}
-	dateFormatted, day, month, _ := helpers.FormatDateToSpanish(parsedDate)
 
-	tmpValues := types.TemplateValues{
-		HolidayName:   nextHoliday.Name,
-		DaysLeft:      daysLeftToHoliday,
-		FormattedDate: dateFormatted,
-		NamedDate: types.NamedDate{
-			Day:   day,
-			Month: month,
-		},
-		RawDate: types.RawDate{
-			Day:   parsedDate.Day(),
-			Month: int(parsedDate.Month()),
-			Year:  parsedDate.Year(),
-		},
-		FullDate:  nextHoliday.Date,
-		Adjacents: nextHoliday.Adjacent,
-		IsToday:   isToday,
+	if isToday {
+		r.Reply(messages.Render(ctx, messages.MessageKeys.IsToday, messages.NewContext(ctx, messages.Holiday(nextHoliday, 0), Describe(scope, nextHoliday))))
+		return
 	}
 
-	message := messages.TemplateMessage(messages.GetMessage(messages.MessageKeys.NextHoliday), tmpValues)
-	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Content: message,
-		},
-	})
+	message := messages.Render(ctx, messages.MessageKeys.NextHoliday, messages.NewContext(ctx, messages.Holiday(nextHoliday, daysLeftToHoliday), Describe(scope, nextHoliday)))
+	r.Reply(message)
 }
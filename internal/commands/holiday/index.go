@@ -0,0 +1,328 @@
+package holidays
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/FGasquez/alum-bot/internal/clock"
+	"github.com/FGasquez/alum-bot/internal/regions"
+	"github.com/FGasquez/alum-bot/internal/settings"
+	"github.com/FGasquez/alum-bot/internal/sources"
+	"github.com/FGasquez/alum-bot/internal/types"
+	"github.com/sirupsen/logrus"
+)
+
+// Index holds the holidays of a year already parsed, sorted and grouped, so
+// queries do not need to process them again. It does not depend on the
+// current day: days left and today flags are filled in when answering.
+type Index struct {
+	Year int
+
+	// holidays sorted by date, weekends only appear inside long weekends
+	days  []types.ParsedHolidays
+	dates []time.Time
+
+	byDate  map[string]int
+	byMonth [13][]int
+
+	// every run of days off longer than a single day, sorted by start date
+	longWeekends []types.LongWeekend
+	weekendEnds  []time.Time
+	// position of the long weekend of each holiday, -1 when it has none
+	longWeekendOf []int
+
+	fingerprint uint64
+}
+
+// NewIndex processes the raw holidays of a year into an index
+func NewIndex(year int, rawHolidays []types.Holiday) *Index {
+	processed := processHolidaysAt(rawHolidays, startOfDay(clock.Now()), false, true, false, false)
+
+	index := &Index{
+		Year:         year,
+		byDate:       map[string]int{},
+		longWeekends: processed.LongWeekends,
+		fingerprint:  fingerprint(rawHolidays),
+	}
+
+	for _, longWeekend := range index.longWeekends {
+		end, _ := time.ParseInLocation(dateLayout, longWeekend.End.Date, time.Local)
+		index.weekendEnds = append(index.weekendEnds, end)
+	}
+
+	longWeekendByDate := map[string]int{}
+	for i, longWeekend := range index.longWeekends {
+		for _, day := range longWeekend.Days {
+			longWeekendByDate[day.Date] = i
+		}
+	}
+
+	for _, holiday := range processed.All {
+		if holiday.Type == types.Weekend {
+			continue
+		}
+		date, _ := time.ParseInLocation(dateLayout, holiday.Date, time.Local)
+
+		position := len(index.days)
+		index.days = append(index.days, holiday)
+		index.dates = append(index.dates, date)
+		index.byDate[holiday.Date] = position
+		index.byMonth[date.Month()] = append(index.byMonth[date.Month()], position)
+
+		if lw, ok := longWeekendByDate[holiday.Date]; ok {
+			index.longWeekendOf = append(index.longWeekendOf, lw)
+		} else {
+			index.longWeekendOf = append(index.longWeekendOf, -1)
+		}
+	}
+
+	return index
+}
+
+// Next returns the first holiday from the day of now on
+func (x *Index) Next(now time.Time, skipWeekends bool, skipToday bool) (types.ParsedHolidays, bool) {
+	today := startOfDay(now)
+	start := sort.Search(len(x.dates), func(i int) bool {
+		return !x.dates[i].Before(today)
+	})
+
+	for i := start; i < len(x.days); i++ {
+		if skipToday && x.dates[i].Equal(today) {
+			continue
+		}
+		if skipWeekends && isWeekend(x.dates[i]) {
+			continue
+		}
+		return x.holiday(i, today), true
+	}
+
+	return types.ParsedHolidays{}, false
+}
+
+// Get returns the holiday of the given yyyy-mm-dd date
+func (x *Index) Get(date string, now time.Time) (types.ParsedHolidays, bool) {
+	i, ok := x.byDate[date]
+	if !ok {
+		return types.ParsedHolidays{}, false
+	}
+	return x.holiday(i, startOfDay(now)), true
+}
+
+// Month returns the holidays of the given month
+func (x *Index) Month(month Months, now time.Time) []types.ParsedHolidays {
+	today := startOfDay(now)
+	positions := x.byMonth[month]
+
+	holidays := make([]types.ParsedHolidays, 0, len(positions))
+	for _, i := range positions {
+		holidays = append(holidays, x.holiday(i, today))
+	}
+	return holidays
+}
+
+// LongWeekends returns the long weekends of the year lasting at least minDays days
+func (x *Index) LongWeekends(minDays int, now time.Time) []types.LongWeekend {
+	today := startOfDay(now)
+
+	longWeekends := make([]types.LongWeekend, 0, len(x.longWeekends))
+	for i := range x.longWeekends {
+		if x.longWeekends[i].Length >= minDays {
+			longWeekends = append(longWeekends, x.longWeekend(i, today))
+		}
+	}
+	return longWeekends
+}
+
+// NextLongWeekend returns the first long weekend lasting at least minDays days
+// that has not ended yet, it may already be in progress
+func (x *Index) NextLongWeekend(minDays int, now time.Time) (types.LongWeekend, bool) {
+	today := startOfDay(now)
+	start := sort.Search(len(x.weekendEnds), func(i int) bool {
+		return !x.weekendEnds[i].Before(today)
+	})
+
+	for i := start; i < len(x.longWeekends); i++ {
+		if x.longWeekends[i].Length >= minDays {
+			return x.longWeekend(i, today), true
+		}
+	}
+
+	return types.LongWeekend{}, false
+}
+
+// holiday returns a copy of the holiday at position i relative to today
+func (x *Index) holiday(i int, today time.Time) types.ParsedHolidays {
+	holiday := withDaysLeft(x.days[i], today)
+	if lw := x.longWeekendOf[i]; lw >= 0 {
+		holiday.Adjacent = x.longWeekend(lw, today).Days
+	}
+	return holiday
+}
+
+// longWeekend returns a copy of the long weekend at position i relative to today
+func (x *Index) longWeekend(i int, today time.Time) types.LongWeekend {
+	longWeekend := x.longWeekends[i]
+
+	days := make([]types.ParsedHolidays, len(longWeekend.Days))
+	for d := range longWeekend.Days {
+		days[d] = withDaysLeft(longWeekend.Days[d], today)
+		days[d].Adjacent = days
+	}
+	longWeekend.Days = days
+	longWeekend.Holidays = nil
+	longWeekend.BridgeDays = nil
+	for _, day := range days {
+		switch day.Type {
+		case types.Weekend:
+		case types.Bridge:
+			longWeekend.BridgeDays = append(longWeekend.BridgeDays, day)
+		default:
+			longWeekend.Holidays = append(longWeekend.Holidays, day)
+		}
+	}
+	longWeekend.Start = days[0]
+	longWeekend.End = days[len(days)-1]
+	longWeekend.DaysLeft = max(longWeekend.Start.DaysLeftToHoliday, 0)
+
+	return longWeekend
+}
+
+func withDaysLeft(holiday types.ParsedHolidays, today time.Time) types.ParsedHolidays {
+	date, _ := time.ParseInLocation(dateLayout, holiday.Date, time.Local)
+	holiday.DaysLeftToHoliday = daysUntil(date, today)
+	holiday.IsToday = date.Equal(today)
+	holiday.Adjacent = nil
+	return holiday
+}
+
+// fingerprint identifies a set of raw holidays, to know when an index must be rebuilt
+func fingerprint(rawHolidays []types.Holiday) uint64 {
+	hash := fnv.New64a()
+	for _, h := range rawHolidays {
+		io.WriteString(hash, h.Date)
+		io.WriteString(hash, h.Type)
+		io.WriteString(hash, h.Name)
+		hash.Write([]byte{0})
+	}
+	return hash.Sum64()
+}
+
+// maxIndexes bounds the indexes kept in memory, the least recently used are
+// dropped first
+const maxIndexes = 512
+
+// indexStamp identifies the inputs an index was built from without reading them
+type indexStamp struct {
+	source   uint64
+	regions  uint64
+	settings uint64
+}
+
+type cachedIndex struct {
+	index *Index
+	stamp indexStamp
+	// stamped is false when the source could not tell its generation
+	stamped bool
+	// used orders the indexes by their last use
+	used uint64
+}
+
+var (
+	indexesMu sync.Mutex
+	indexes   = map[string]*cachedIndex{}
+	indexUses uint64
+)
+
+// GetIndex returns the index of the holidays of the scope for the given year.
+// While the holidays of the source, the region files and the settings stay the
+// same the index is returned as it is, otherwise the holidays are read again
+// and the index is only rebuilt when they changed.
+func GetIndex(ctx context.Context, scope Scope, year int) (*Index, error) {
+	key := fmt.Sprintf("%s|%s|%s|%d", scope.country(), scope.Region, scope.GuildID, year)
+	stamp, stamped := scope.stamp(year)
+
+	if stamped {
+		indexesMu.Lock()
+		if cached, ok := indexes[key]; ok && cached.stamped && cached.stamp == stamp {
+			indexUses++
+			cached.used = indexUses
+			indexesMu.Unlock()
+			return cached.index, nil
+		}
+		indexesMu.Unlock()
+	}
+
+	provider, err := sources.Get(scope.country())
+	if err != nil {
+		return nil, err
+	}
+
+	rawHolidays, err := provider.Holidays(ctx, year)
+	if err != nil {
+		return nil, err
+	}
+	rawHolidays = mergeHolidays(rawHolidays, scope.holidays(year))
+	current := fingerprint(rawHolidays)
+
+	indexesMu.Lock()
+	defer indexesMu.Unlock()
+
+	indexUses++
+	if cached, ok := indexes[key]; ok && cached.index.fingerprint == current {
+		cached.stamp, cached.stamped, cached.used = stamp, stamped, indexUses
+		return cached.index, nil
+	}
+
+	logrus.WithField("scope", key).Debug("Building holidays index")
+	index := NewIndex(year, rawHolidays)
+	indexes[key] = &cachedIndex{index: index, stamp: stamp, stamped: stamped, used: indexUses}
+	evictIndexes(key)
+	return index, nil
+}
+
+// evictIndexes drops the indexes of past years and, above maxIndexes, the
+// least recently used ones, keeping the one of key. Callers must hold indexesMu.
+func evictIndexes(key string) {
+	year := clock.Now().Year()
+	for k, cached := range indexes {
+		if k != key && cached.index.Year < year {
+			delete(indexes, k)
+		}
+	}
+
+	for len(indexes) > maxIndexes {
+		oldest := ""
+		for k, cached := range indexes {
+			if k != key && (oldest == "" || cached.used < indexes[oldest].used) {
+				oldest = k
+			}
+		}
+		delete(indexes, oldest)
+	}
+}
+
+// stamp returns the stamp of the inputs of the scope for the year, false when
+// the source cannot tell it without fetching the holidays
+func (sc Scope) stamp(year int) (indexStamp, bool) {
+	source, ok := sources.Generation(sc.country(), year)
+	if !ok {
+		return indexStamp{}, false
+	}
+
+	stamp := indexStamp{source: source}
+	if sc.Region != "" {
+		if _, err := regions.Get(sc.Region); err != nil {
+			return indexStamp{}, false
+		}
+		stamp.regions = regions.Generation()
+	}
+	if sc.GuildID != "" {
+		stamp.settings = settings.Revision()
+	}
+	return stamp, true
+}
@@ -0,0 +1,137 @@
+package holidays
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/FGasquez/alum-bot/internal/cache"
+	"github.com/FGasquez/alum-bot/internal/clock"
+	"github.com/FGasquez/alum-bot/internal/sources"
+	"github.com/FGasquez/alum-bot/internal/types"
+)
+
+// memoryProvider serves the same holidays for every year and counts the calls
+type memoryProvider struct {
+	holidays []types.Holiday
+	calls    *int
+}
+
+func (m memoryProvider) Holidays(_ context.Context, _ int) ([]types.Holiday, error) {
+	*m.calls++
+	return m.holidays, nil
+}
+
+var holidays2025 = []types.Holiday{
+	{Date: "2025-01-01", Type: "inamovible", Name: "Año Nuevo"},
+	{Date: "2025-03-03", Type: "inamovible", Name: "Carnaval"},
+	{Date: "2025-03-04", Type: "inamovible", Name: "Carnaval"},
+	{Date: "2025-03-24", Type: "inamovible", Name: "Día Nacional de la Memoria por la Verdad y la Justicia"},
+	{Date: "2025-04-02", Type: "inamovible", Name: "Día del Veterano y de los Caídos en la Guerra de Malvinas"},
+	{Date: "2025-04-18", Type: "inamovible", Name: "Viernes Santo"},
+	{Date: "2025-05-01", Type: "inamovible", Name: "Día del Trabajador"},
+	{Date: "2025-05-02", Type: types.Bridge, Name: "Puente turístico"},
+	{Date: "2025-05-25", Type: "inamovible", Name: "Día de la Revolución de Mayo"},
+	{Date: "2025-06-16", Type: "trasladable", Name: "Paso a la Inmortalidad del General Martín Miguel de Güemes"},
+	{Date: "2025-06-20", Type: "inamovible", Name: "Paso a la Inmortalidad del General Manuel Belgrano"},
+	{Date: "2025-07-09", Type: "inamovible", Name: "Día de la Independencia"},
+	{Date: "2025-08-15", Type: types.Bridge, Name: "Puente turístico"},
+	{Date: "2025-08-17", Type: "trasladable", Name: "Paso a la Inmortalidad del General José de San Martín"},
+	{Date: "2025-10-12", Type: "trasladable", Name: "Día del Respeto a la Diversidad Cultural"},
+	{Date: "2025-11-21", Type: types.Bridge, Name: "Puente turístico"},
+	{Date: "2025-11-24", Type: "trasladable", Name: "Día de la Soberanía Nacional"},
+	{Date: "2025-12-08", Type: "inamovible", Name: "Inmaculada Concepción de María"},
+	{Date: "2025-12-25", Type: "inamovible", Name: "Navidad"},
+}
+
+// registerMemory registers a cached in-memory source for country, like the
+// remote sources are, and returns the count of calls reaching it
+func registerMemory(tb testing.TB, country string) *int {
+	tb.Helper()
+	calls := new(int)
+	sources.Register(country, sources.Cached{
+		Country:  country,
+		Provider: memoryProvider{holidays: holidays2025, calls: calls},
+		Cache:    cache.New(tb.TempDir(), time.Hour, 0),
+	})
+	return calls
+}
+
+func TestGetIndexReusesIndex(t *testing.T) {
+	calls := registerMemory(t, "ZA")
+	scope := Scope{Country: "ZA"}
+
+	first, err := GetIndex(context.Background(), scope, 2025)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := GetIndex(context.Background(), scope, 2025)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first != second {
+		t.Error("the index was rebuilt without any change")
+	}
+	if *calls != 1 {
+		t.Errorf("the source was called %d times, want 1", *calls)
+	}
+}
+
+func BenchmarkGetIndex(b *testing.B) {
+	registerMemory(b, "ZB")
+	scope := Scope{Country: "ZB"}
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := GetIndex(ctx, scope, 2025); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNextHoliday(b *testing.B) {
+	registerMemory(b, "ZC")
+	scope := Scope{Country: "ZC"}
+	ctx := context.Background()
+	date := time.Date(2025, 7, 1, 12, 0, 0, 0, time.Local)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if next, _ := NextHoliday(ctx, scope, date, true, false); next == nil {
+			b.Fatal("no next holiday")
+		}
+	}
+}
+
+// BenchmarkHolidaysProcessor answers the same question as BenchmarkNextHoliday
+// the way every request did before the index: decoding and processing the
+// holidays of the year each time
+func BenchmarkHolidaysProcessor(b *testing.B) {
+	data, err := json.Marshal(holidays2025)
+	if err != nil {
+		b.Fatal(err)
+	}
+	clock.Set(clock.Fixed(time.Date(2025, 7, 1, 12, 0, 0, 0, time.Local)))
+	b.Cleanup(func() { clock.Set(nil) })
+
+	b.ReportAllocs()
+	for b.Loop() {
+		processed, err := HolidaysProcessor(data, true, true, true, false)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if processed.Next.Date == "" {
+			b.Fatal("no next holiday")
+		}
+	}
+}
+
+func BenchmarkNewIndex(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		NewIndex(2025, holidays2025)
+	}
+}
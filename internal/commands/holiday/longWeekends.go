@@ -0,0 +1,75 @@
+package holidays
+
+import (
+	"context"
+
+	"github.com/FGasquez/alum-bot/internal/clock"
+	"github.com/FGasquez/alum-bot/internal/helpers"
+	"github.com/FGasquez/alum-bot/internal/messages"
+	"github.com/FGasquez/alum-bot/internal/responder"
+	"github.com/bwmarrin/discordgo"
+)
+
+const LongWeekendsCommandName = "long-weekends"
+
+var LongWeekendsCommand = discordgo.ApplicationCommand{
+	Name:        LongWeekendsCommandName,
+	Description: "List all the long weekends of the year",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "year",
+			Description: "the year to list (default: current year)",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "min-days",
+			Description: "minimum days off in a row (default: 3)",
+			Required:    false,
+			MinValue:    &minLongWeekendDays,
+		},
+		countryOption,
+		regionOption,
+		privateOption,
+	},
+}
+
+func handleLongWeekendsCommand(ctx context.Context, r responder.Responder, i *discordgo.InteractionCreate) {
+	year := clock.Now().Year()
+	minDays := DefaultLongWeekendMinDays
+
+	params := helpers.GetParams(i.ApplicationCommandData().Options)
+	if _, ok := params["year"]; ok {
+		year = helpers.IntParam(params["year"])
+	}
+	if _, ok := params["min-days"]; ok {
+		minDays = helpers.IntParam(params["min-days"])
+	}
+
+	scope, err := ResolveScope(i, params)
+	if err != nil {
+		respondScopeError(ctx, r, err)
+		return
+	}
+	applyPrivacy(r, i, params)
+	deferUnlessCached(r, scope, year)
+
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	longWeekends, err := GetLongWeekends(ctx, scope, year, minDays)
+	if err != nil {
+		respondHolidaysError(ctx, r, err)
+		return
+	}
+
+	values := messages.NewContext(ctx, messages.LongWeekends(year, minDays, longWeekends))
+
+	messageKey := messages.MessageKeys.LongWeekends
+	if len(longWeekends) == 0 {
+		messageKey = messages.MessageKeys.NoLongWeekends
+	}
+
+	r.Reply(messages.Render(ctx, messageKey, values))
+}
@@ -0,0 +1,190 @@
+package holidays
+
+import (
+	"context"
+	"strings"
+
+	"github.com/FGasquez/alum-bot/internal/helpers"
+	"github.com/FGasquez/alum-bot/internal/logging"
+	"github.com/FGasquez/alum-bot/internal/messages"
+	"github.com/FGasquez/alum-bot/internal/responder"
+	"github.com/FGasquez/alum-bot/internal/settings"
+	"github.com/bwmarrin/discordgo"
+)
+
+const MessagesCommandName = "messages"
+
+// templateInputID is the custom ID of the text input of the template modal
+const templateInputID = "template"
+
+var messageKeyOption = &discordgo.ApplicationCommandOption{
+	Type:         discordgo.ApplicationCommandOptionString,
+	Name:         "key",
+	Description:  "message key, e.g. nextHoliday",
+	Autocomplete: true,
+}
+
+var MessagesCommand = discordgo.ApplicationCommand{
+	Name:                     MessagesCommandName,
+	Description:              "Customize the messages of this server",
+	DefaultMemberPermissions: &manageGuildPermission,
+	Contexts:                 &[]discordgo.InteractionContextType{discordgo.InteractionContextGuild},
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "set",
+			Description: "Edit the template of a message for this server",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         messageKeyOption.Type,
+					Name:         messageKeyOption.Name,
+					Description:  messageKeyOption.Description,
+					Autocomplete: true,
+					Required:     true,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "reset",
+			Description: "Go back to the default template of a message, or of all of them",
+			Options: []*discordgo.ApplicationCommandOption{
+				messageKeyOption,
+			},
+		},
+	},
+}
+
+func handleMessagesCommand(ctx context.Context, r responder.Responder, i *discordgo.InteractionCreate) {
+	if i.GuildID == "" {
+		return
+	}
+	r.SetEphemeral(true)
+
+	if !helpers.CanManageGuild(i) {
+		r.ReplyEphemeral(messages.Render(ctx, messages.MessageKeys.MissingPermissions, messages.NewContext(ctx)))
+		return
+	}
+
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return
+	}
+	params := helpers.GetParams(options)
+	key, _ := params[messageKeyOption.Name].(string)
+	key = strings.TrimSpace(key)
+
+	if key != "" && !messages.IsKey(key) {
+		r.ReplyEphemeral(messages.Render(ctx, messages.MessageKeys.UnknownMessageKey, messages.NewContext(ctx, func(c *messages.Context) {
+			c.Key = key
+		})))
+		return
+	}
+
+	switch options[0].Name {
+	case "set":
+		r.Modal(templateModal(key, messages.Get(ctx, key)))
+	case "reset":
+		err := settings.UpdateGuild(i.GuildID, func(st *settings.Settings) {
+			if key == "" {
+				st.Messages = nil
+				return
+			}
+			delete(st.Messages, key)
+			if len(st.Messages) == 0 {
+				st.Messages = nil
+			}
+		})
+		if err != nil {
+			logging.FromContext(ctx).WithError(err).Error("Failed to reset messages")
+			r.ReplyError(messages.MessageKeys.FailedToSaveSettings)
+			return
+		}
+
+		r.ReplyEphemeral(messages.Render(ctx, messages.MessageKeys.MessageReset, messages.NewContext(ctx, func(c *messages.Context) {
+			c.Key = key
+		})))
+	}
+}
+
+// templateModal asks for the template of key, filled in with the current one
+func templateModal(key string, current string) *discordgo.InteractionResponseData {
+	return &discordgo.InteractionResponseData{
+		CustomID: MessagesCommandName + ":" + key,
+		Title:    "Template of " + key,
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.TextInput{
+						CustomID:  templateInputID,
+						Label:     "Template",
+						Style:     discordgo.TextInputParagraph,
+						Value:     current,
+						Required:  true,
+						MaxLength: 4000,
+					},
+				},
+			},
+		},
+	}
+}
+
+// handleMessagesModal validates the template submitted for a message and saves it for the guild
+func handleMessagesModal(ctx context.Context, r responder.Responder, i *discordgo.InteractionCreate) {
+	if i.GuildID == "" {
+		return
+	}
+	r.SetEphemeral(true)
+
+	if !helpers.CanManageGuild(i) {
+		r.ReplyEphemeral(messages.Render(ctx, messages.MessageKeys.MissingPermissions, messages.NewContext(ctx)))
+		return
+	}
+
+	data := i.ModalSubmitData()
+	_, key, _ := strings.Cut(data.CustomID, ":")
+	template := modalValue(data.Components, templateInputID)
+
+	if err := messages.Validate(key, template); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("key", key).Info("Rejected message template")
+		r.ReplyEphemeral(messages.Render(ctx, messages.MessageKeys.InvalidTemplate, messages.NewContext(ctx, func(c *messages.Context) {
+			c.Key = key
+			c.Error = err.Error()
+		})))
+		return
+	}
+
+	err := settings.UpdateGuild(i.GuildID, func(st *settings.Settings) {
+		if st.Messages == nil {
+			st.Messages = map[string]string{}
+		}
+		st.Messages[key] = template
+	})
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Failed to save message template")
+		r.ReplyError(messages.MessageKeys.FailedToSaveSettings)
+		return
+	}
+
+	r.ReplyEphemeral(messages.Render(ctx, messages.MessageKeys.MessageSet, messages.NewContext(ctx, func(c *messages.Context) {
+		c.Key = key
+		c.Preview = messages.TemplateMessage(template, messages.Sample())
+	})))
+}
+
+// modalValue returns the value of the text input with the given custom ID
+func modalValue(components []discordgo.MessageComponent, customID string) string {
+	for _, component := range components {
+		switch c := component.(type) {
+		case *discordgo.ActionsRow:
+			if value := modalValue(c.Components, customID); value != "" {
+				return value
+			}
+		case *discordgo.TextInput:
+			if c.CustomID == customID {
+				return c.Value
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,161 @@
+package holidays_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FGasquez/alum-bot/internal/commands/holiday/holidaytest"
+	"github.com/FGasquez/alum-bot/internal/settings"
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestMessagesSetOpensModal(t *testing.T) {
+	h := newHarness(t, date(time.July, 1))
+	admin := holidaytest.Permissions(discordgo.PermissionManageGuild)
+
+	responses, err := h.Run(holidaytest.Interaction("messages", admin,
+		holidaytest.Subcommand("set", map[string]interface{}{"key": "nextHoliday"})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(responses) != 1 || responses[0].Modal == nil {
+		t.Fatalf("got %+v, want a modal", responses)
+	}
+	modal := responses[0].Modal
+	if modal.CustomID != "messages:nextHoliday" {
+		t.Errorf("custom ID %q, want messages:nextHoliday", modal.CustomID)
+	}
+	input := modal.Components[0].(discordgo.ActionsRow).Components[0].(discordgo.TextInput)
+	if !strings.HasPrefix(input.Value, "🎉 El próximo feriado es") {
+		t.Errorf("the modal is filled in with %q, want the current template", input.Value)
+	}
+
+	got, err := h.Reply(holidaytest.Interaction("messages", admin,
+		holidaytest.Subcommand("set", map[string]interface{}{"key": "nextHolidays"})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "❌ **nextHolidays** no es una clave de mensaje"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestMessagesModalSubmit(t *testing.T) {
+	admin := holidaytest.Permissions(discordgo.PermissionManageGuild)
+	tests := []struct {
+		name     string
+		customID string
+		template string
+		options  []holidaytest.Option
+		want     string
+		// saved reports the template is used afterwards by next-holiday
+		saved bool
+	}{
+		{
+			name:     "valid",
+			customID: "messages:nextHoliday",
+			template: "Próximo: {{ .HolidayName }}",
+			options:  []holidaytest.Option{admin},
+			want:     "✅ Plantilla **nextHoliday** guardada para este servidor, vista previa:\nPróximo: ",
+			saved:    true,
+		},
+		{
+			name:     "without permissions",
+			customID: "messages:nextHoliday",
+			template: "Próximo: {{ .HolidayName }}",
+			want:     "🔒 Necesitás el permiso de Gestionar servidor para hacer eso.",
+		},
+		{
+			name:     "unknown field",
+			customID: "messages:nextHoliday",
+			template: "Próximo: {{ .Feriado }}",
+			options:  []holidaytest.Option{admin},
+			want:     "❌ La plantilla de **nextHoliday** no es válida: ",
+		},
+		{
+			name:     "unparsable",
+			customID: "messages:nextHoliday",
+			template: "Próximo: {{ .HolidayName",
+			options:  []holidaytest.Option{admin},
+			want:     "❌ La plantilla de **nextHoliday** no es válida: ",
+		},
+		{
+			name:     "unknown key",
+			customID: "messages:nextHolidays",
+			template: "Próximo: {{ .HolidayName }}",
+			options:  []holidaytest.Option{admin},
+			want:     "❌ La plantilla de **nextHolidays** no es válida: ",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := newHarness(t, date(time.July, 1))
+
+			responses, err := h.Run(holidaytest.ModalSubmit(test.customID, map[string]string{"template": test.template}, test.options...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(responses) != 1 {
+				t.Fatalf("got %d responses, want 1", len(responses))
+			}
+			if got := responses[0].Content; !strings.HasPrefix(got, test.want) {
+				t.Errorf("got %q, want it to start with %q", got, test.want)
+			}
+			if !responses[0].Ephemeral {
+				t.Error("the response is public")
+			}
+
+			if saved := settings.GetGuild("guild").Messages != nil; saved != test.saved {
+				t.Errorf("saved %t, want %t", saved, test.saved)
+			}
+			got, err := h.Reply(holidaytest.Interaction("next-holiday"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if custom := got == "Próximo: Día de la Independencia"; custom != test.saved {
+				t.Errorf("next holiday %q, custom template used %t, want %t", got, custom, test.saved)
+			}
+		})
+	}
+}
+
+func TestMessagesReset(t *testing.T) {
+	h := newHarness(t, date(time.July, 1))
+	admin := holidaytest.Permissions(discordgo.PermissionManageGuild)
+
+	for _, key := range []string{"nextHoliday", "daysLeft"} {
+		if _, err := h.Run(holidaytest.ModalSubmit("messages:"+key, map[string]string{"template": "custom " + key}, admin)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, _ := h.Reply(holidaytest.Interaction("next-holiday", holidaytest.Guild("other"))); got == "custom nextHoliday" {
+		t.Error("another guild uses the template")
+	}
+
+	got, err := h.Reply(holidaytest.Interaction("messages", admin, holidaytest.Subcommand("reset", map[string]interface{}{"key": "nextHoliday"})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "♻️ Plantilla **nextHoliday** restablecida"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, _ := h.Reply(holidaytest.Interaction("next-holiday")); got == "custom nextHoliday" {
+		t.Error("the reset template is still used")
+	}
+	if got, _ := h.Reply(holidaytest.Interaction("days-left")); got != "custom daysLeft" {
+		t.Errorf("days left %q, want the template that was not reset", got)
+	}
+
+	got, err = h.Reply(holidaytest.Interaction("messages", admin, holidaytest.Subcommand("reset", nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "♻️ Todas las plantillas fueron restablecidas"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if messages := settings.GetGuild("guild").Messages; messages != nil {
+		t.Errorf("kept %v", messages)
+	}
+}
@@ -1,93 +1,99 @@
 package holidays
 
 import (
-	"time"
+	"context"
 
+	"github.com/FGasquez/alum-bot/internal/clock"
 	"github.com/FGasquez/alum-bot/internal/helpers"
 	"github.com/FGasquez/alum-bot/internal/messages"
+	"github.com/FGasquez/alum-bot/internal/responder"
 	"github.com/FGasquez/alum-bot/internal/types"
 	"github.com/bwmarrin/discordgo"
 )
 
 const HolidaysLargeCommandName = "next-large-holiday"
 
+var minLongWeekendDays = float64(2)
+
 var HolidaysLargeCommands = discordgo.ApplicationCommand{
 	Name:        HolidaysLargeCommandName,
 	Description: "Get the next large holiday",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "min-days",
+			Description: "minimum days off in a row (default: 3)",
+			Required:    false,
+			MinValue:    &minLongWeekendDays,
+		},
+		countryOption,
+		regionOption,
+		privateOption,
+	},
 }
 
-func GetNextLargeHoliday(holiday types.ProcessedHolidays) *types.ParsedHolidays {
-	for _, holiday := range holiday.All {
-		if len(holiday.Adjacent) > 0 {
-			return &holiday
+// GetNextLargeHoliday returns the first long weekend lasting at least minDays
+// days that has not ended yet, looking into the next year when needed
+func GetNextLargeHoliday(ctx context.Context, scope Scope, minDays int) (*types.LongWeekend, error) {
+	now := clock.Now()
+	for _, year := range []int{now.Year(), now.Year() + 1} {
+		index, err := GetIndex(ctx, scope, year)
+		if err != nil {
+			return nil, err
+		}
+
+		if longWeekend, ok := index.NextLongWeekend(minDays, now); ok {
+			return &longWeekend, nil
 		}
 	}
 
-	return nil
+	return nil, nil
 }
 
-var HolidayLargeCommandHandlers = func(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	holidays, err := GetHolidays(time.Now().Year(), true, true, false, false)
-	if err != nil {
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "❌ Failed to retrieve holidays. Please try again later.",
-			},
-		})
-
-		return
+// longWeekendName names a long weekend after its first holiday, or its first bridge day
+func longWeekendName(longWeekend *types.LongWeekend) string {
+	if len(longWeekend.Holidays) > 0 {
+		return longWeekend.Holidays[0].Name
 	}
+	if len(longWeekend.BridgeDays) > 0 {
+		return longWeekend.BridgeDays[0].Name
+	}
+	return longWeekend.Start.Name
+}
 
-	largeHolidays := GetNextLargeHoliday(holidays)
+func handleHolidayLargeCommand(ctx context.Context, r responder.Responder, i *discordgo.InteractionCreate) {
+	minDays := DefaultLongWeekendMinDays
 
-	if largeHolidays == nil {
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "❌ No upcoming large holidays found.",
-			},
-		})
+	params := helpers.GetParams(i.ApplicationCommandData().Options)
+	if _, ok := params["min-days"]; ok {
+		minDays = helpers.IntParam(params["min-days"])
+	}
+
+	scope, err := ResolveScope(i, params)
+	if err != nil {
+		respondScopeError(ctx, r, err)
 		return
 	}
+	applyPrivacy(r, i, params)
+	deferUnlessCached(r, scope, clock.Now().Year())
 
-	dateFormatted, day, month, _ := helpers.FormatDateToSpanishUnparsed(largeHolidays.Date)
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
 
-	parsedDate, err := time.Parse("2006-01-02", largeHolidays.Date)
+	longWeekend, err := GetNextLargeHoliday(ctx, scope, minDays)
 	if err != nil {
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "❌ Failed to retrieve holidays. Please try again later.",
-			},
-		})
-
+		respondHolidaysError(ctx, r, err)
 		return
 	}
 
-	tmpValues := types.TemplateValues{
-		HolidayName:   largeHolidays.Name,
-		DaysLeft:      largeHolidays.DaysLeftToHoliday,
-		FormattedDate: dateFormatted,
-		NamedDate: types.NamedDate{
-			Day:   day,
-			Month: month,
-		},
-		RawDate: types.RawDate{
-			Day:   parsedDate.Day(),
-			Month: int(parsedDate.Month()),
-			Year:  parsedDate.Year(),
-		},
-		FullDate:  largeHolidays.Date,
-		Adjacents: largeHolidays.Adjacent,
-		IsToday:   largeHolidays.IsToday,
+	if longWeekend == nil {
+		r.ReplyError(messages.MessageKeys.NoLargeHolidays)
+		return
 	}
 
-	message := messages.TemplateMessage(messages.GetMessage(messages.MessageKeys.NextLargeHoliday), tmpValues)
-	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Content: message,
-		},
-	})
+	values := messages.NewContext(ctx, messages.Holiday(longWeekend.Start, longWeekend.DaysLeft), Describe(scope, longWeekend.Start), messages.LongWeekend(longWeekend))
+	values.HolidayName = longWeekendName(longWeekend)
+
+	message := messages.Render(ctx, messages.MessageKeys.NextLargeHoliday, values)
+	r.Reply(message)
 }
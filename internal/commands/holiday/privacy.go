@@ -0,0 +1,80 @@
+package holidays
+
+import (
+	"context"
+
+	"github.com/FGasquez/alum-bot/internal/helpers"
+	"github.com/FGasquez/alum-bot/internal/logging"
+	"github.com/FGasquez/alum-bot/internal/messages"
+	"github.com/FGasquez/alum-bot/internal/responder"
+	"github.com/FGasquez/alum-bot/internal/settings"
+	"github.com/bwmarrin/discordgo"
+)
+
+const PrivacyCommandName = "privacy"
+
+var privateOption = &discordgo.ApplicationCommandOption{
+	Type:        discordgo.ApplicationCommandOptionBoolean,
+	Name:        "private",
+	Description: "only you can see the response (default: the server setting)",
+	Required:    false,
+}
+
+var PrivacyCommand = discordgo.ApplicationCommand{
+	Name:                     PrivacyCommandName,
+	Description:              "Choose whether the responses in this server are private by default",
+	DefaultMemberPermissions: &manageGuildPermission,
+	Contexts:                 &[]discordgo.InteractionContextType{discordgo.InteractionContextGuild},
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        privateOption.Name,
+			Description: "make the responses private, leave it out to see the current setting",
+			Required:    false,
+		},
+	},
+}
+
+// applyPrivacy makes the responses ephemeral when asked for with the private
+// option, or when the guild has private responses by default
+func applyPrivacy(r responder.Responder, i *discordgo.InteractionCreate, params map[string]interface{}) {
+	private := i.GuildID != "" && settings.GetGuild(i.GuildID).Private
+	if value, ok := params[privateOption.Name].(bool); ok {
+		private = value
+	}
+	r.SetEphemeral(private)
+}
+
+func handlePrivacyCommand(ctx context.Context, r responder.Responder, i *discordgo.InteractionCreate) {
+	if i.GuildID == "" {
+		return
+	}
+	r.SetEphemeral(true)
+
+	params := helpers.GetParams(i.ApplicationCommandData().Options)
+	private, ok := params[privateOption.Name].(bool)
+	if !ok {
+		r.Reply(messages.Render(ctx, messages.MessageKeys.PrivacyShow, messages.NewContext(ctx, func(c *messages.Context) {
+			c.Private = settings.GetGuild(i.GuildID).Private
+		})))
+		return
+	}
+
+	if !helpers.CanManageGuild(i) {
+		r.Reply(messages.Render(ctx, messages.MessageKeys.MissingPermissions, messages.NewContext(ctx)))
+		return
+	}
+
+	err := settings.UpdateGuild(i.GuildID, func(st *settings.Settings) {
+		st.Private = private
+	})
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Failed to save privacy setting")
+		r.ReplyError(messages.MessageKeys.FailedToSaveSettings)
+		return
+	}
+
+	r.Reply(messages.Render(ctx, messages.MessageKeys.PrivacySet, messages.NewContext(ctx, func(c *messages.Context) {
+		c.Private = private
+	})))
+}
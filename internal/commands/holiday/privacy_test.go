@@ -0,0 +1,108 @@
+package holidays_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FGasquez/alum-bot/internal/commands/holiday/holidaytest"
+	"github.com/FGasquez/alum-bot/internal/settings"
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestPrivateResponses(t *testing.T) {
+	tests := []struct {
+		name         string
+		guildPrivate bool
+		options      []holidaytest.Option
+		want         bool
+	}{
+		{name: "public by default"},
+		{name: "private option", options: []holidaytest.Option{holidaytest.Param("private", true)}, want: true},
+		{name: "explicitly public", options: []holidaytest.Option{holidaytest.Param("private", false)}},
+		{name: "private guild", guildPrivate: true, want: true},
+		{name: "public option in a private guild", guildPrivate: true, options: []holidaytest.Option{holidaytest.Param("private", false)}},
+		{name: "private option in a private guild", guildPrivate: true, options: []holidaytest.Option{holidaytest.Param("private", true)}, want: true},
+		{name: "private option in a DM", options: []holidaytest.Option{holidaytest.Guild(""), holidaytest.Param("private", true)}, want: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := newHarness(t, date(time.July, 1))
+			if test.guildPrivate {
+				setPrivacy(t, h, true)
+			}
+
+			commands := map[string][]holidaytest.Option{
+				"next-holiday":       nil,
+				"days-left":          nil,
+				"next-large-holiday": nil,
+				"holidays-of-month":  {holidaytest.Param("month", 7)},
+				"long-weekends":      nil,
+			}
+			for command, options := range commands {
+				responses, err := h.Run(holidaytest.Interaction(command, append(options, test.options...)...))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(responses) == 0 {
+					t.Fatalf("%s did not respond", command)
+				}
+				for _, response := range responses {
+					if response.Ephemeral != test.want {
+						t.Errorf("%s responded with ephemeral %t, want %t", command, response.Ephemeral, test.want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPrivacyCommand(t *testing.T) {
+	h := newHarness(t, date(time.July, 1))
+
+	responses, err := h.Run(holidaytest.Interaction("privacy", holidaytest.Param("private", true)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := responses[0].Content, "🔒 Necesitás el permiso de Gestionar servidor para hacer eso."; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if settings.GetGuild("guild").Private {
+		t.Error("a member without permissions made the guild private")
+	}
+
+	setPrivacy(t, h, true)
+	if !settings.GetGuild("guild").Private {
+		t.Error("the guild is not private")
+	}
+
+	responses, err = h.Run(holidaytest.Interaction("privacy"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := responses[0].Content, "🔒 Las respuestas en este servidor son privadas por defecto"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if !responses[0].Ephemeral {
+		t.Error("the privacy setting was shown publicly")
+	}
+}
+
+// setPrivacy changes the default privacy of the guild as an administrator
+func setPrivacy(t *testing.T, h *holidaytest.Harness, private bool) {
+	t.Helper()
+	got, err := h.Reply(holidaytest.Interaction("privacy",
+		holidaytest.Param("private", private),
+		holidaytest.Permissions(discordgo.PermissionManageGuild),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "🔒 Las respuestas en este servidor ahora son públicas por defecto"
+	if private {
+		want = "🔒 Las respuestas en este servidor ahora son privadas por defecto"
+	}
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
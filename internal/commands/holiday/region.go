@@ -0,0 +1,125 @@
+package holidays
+
+import (
+	"context"
+
+	"github.com/FGasquez/alum-bot/internal/helpers"
+	"github.com/FGasquez/alum-bot/internal/logging"
+	"github.com/FGasquez/alum-bot/internal/messages"
+	"github.com/FGasquez/alum-bot/internal/regions"
+	"github.com/FGasquez/alum-bot/internal/responder"
+	"github.com/FGasquez/alum-bot/internal/settings"
+	"github.com/bwmarrin/discordgo"
+)
+
+const RegionCommandName = "region"
+
+var guildOption = &discordgo.ApplicationCommandOption{
+	Type:        discordgo.ApplicationCommandOptionBoolean,
+	Name:        "guild",
+	Description: "apply to the whole server instead of only to you (requires Manage Server)",
+	Required:    false,
+}
+
+var RegionCommand = discordgo.ApplicationCommand{
+	Name:        RegionCommandName,
+	Description: "Manage the province used to include regional holidays",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "set",
+			Description: "Set your region, or the server region",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         regionOption.Name,
+					Description:  "the province to use",
+					Required:     true,
+					Autocomplete: true,
+				},
+				guildOption,
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "clear",
+			Description: "Go back to national holidays only",
+			Options: []*discordgo.ApplicationCommandOption{
+				guildOption,
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "show",
+			Description: "Show your region and the server region",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "list",
+			Description: "List the available regions",
+		},
+	},
+}
+
+func handleRegionCommand(ctx context.Context, r responder.Responder, i *discordgo.InteractionCreate) {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return
+	}
+
+	params := helpers.GetParams(options)
+	guild, _ := params[guildOption.Name].(bool)
+	if guild && !helpers.CanManageGuild(i) {
+		r.ReplyEphemeral(messages.Render(ctx, messages.MessageKeys.MissingPermissions, messages.NewContext(ctx)))
+		return
+	}
+
+	var region regions.Region
+	var message string
+	switch options[0].Name {
+	case "set":
+		var err error
+		region, err = regions.Get(params[regionOption.Name].(string))
+		if err != nil {
+			respondScopeError(ctx, r, err)
+			return
+		}
+		message = messages.MessageKeys.RegionSet
+	case "clear":
+		message = messages.MessageKeys.RegionCleared
+	case "show":
+		r.ReplyEphemeral(messages.Render(ctx, messages.MessageKeys.RegionShow, messages.NewContext(ctx, func(c *messages.Context) {
+			c.UserRegion = settings.GetUser(helpers.UserID(i)).Region
+			c.GuildRegion = settings.GetGuild(i.GuildID).Region
+		})))
+		return
+	case "list":
+		r.ReplyEphemeral(messages.Render(ctx, messages.MessageKeys.RegionList, messages.NewContext(ctx, func(c *messages.Context) {
+			c.Regions = regionList(regions.List())
+		})))
+		return
+	default:
+		return
+	}
+
+	err := updateSettings(i, guild, func(st *settings.Settings) { st.Region = region.Code })
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Failed to save region settings")
+		r.SetEphemeral(true)
+		r.ReplyError(messages.MessageKeys.FailedToSaveSettings)
+		return
+	}
+
+	r.ReplyEphemeral(messages.Render(ctx, message, messages.NewContext(ctx, func(c *messages.Context) {
+		c.Region = region.Name
+		c.Guild = guild
+	})))
+}
+
+// updateSettings applies update to the guild settings, or to the settings of the user that triggered the interaction
+func updateSettings(i *discordgo.InteractionCreate, guild bool, update func(*settings.Settings)) error {
+	if guild {
+		return settings.UpdateGuild(i.GuildID, update)
+	}
+	return settings.UpdateUser(helpers.UserID(i), update)
+}
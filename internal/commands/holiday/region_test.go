@@ -0,0 +1,174 @@
+package holidays_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FGasquez/alum-bot/internal/commands/holiday/holidaytest"
+	"github.com/FGasquez/alum-bot/internal/settings"
+	"github.com/bwmarrin/discordgo"
+	"github.com/spf13/viper"
+)
+
+// withRegions serves a Córdoba region with a holiday on the 7th of July
+func withRegions(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	region := "name: Córdoba\ncountry: AR\nholidays:\n  - date: \"07-07\"\n    name: Día de Córdoba\n"
+	if err := os.WriteFile(filepath.Join(dir, "cordoba.yaml"), []byte(region), 0644); err != nil {
+		t.Fatal(err)
+	}
+	viper.Set("regions-dir", dir)
+	t.Cleanup(func() { viper.Set("regions-dir", nil) })
+}
+
+func TestRegionCommand(t *testing.T) {
+	admin := holidaytest.Permissions(discordgo.PermissionManageGuild)
+	tests := []struct {
+		name        string
+		options     []holidaytest.Option
+		want        string
+		userRegion  string
+		guildRegion string
+	}{
+		{
+			name:       "set",
+			options:    []holidaytest.Option{holidaytest.Subcommand("set", map[string]interface{}{"region": "Cordoba "})},
+			want:       "📍 Provincia configurada: **Córdoba**",
+			userRegion: "cordoba",
+		},
+		{
+			name:        "set for the guild",
+			options:     []holidaytest.Option{admin, holidaytest.Subcommand("set", map[string]interface{}{"region": "cordoba", "guild": true})},
+			want:        "📍 Provincia configurada: **Córdoba** para todo el servidor",
+			guildRegion: "cordoba",
+		},
+		{
+			name:    "set for the guild without permissions",
+			options: []holidaytest.Option{holidaytest.Subcommand("set", map[string]interface{}{"region": "cordoba", "guild": true})},
+			want:    "🔒 Necesitás el permiso de Gestionar servidor para hacer eso.",
+		},
+		{
+			name:    "unknown region",
+			options: []holidaytest.Option{holidaytest.Subcommand("set", map[string]interface{}{"region": "atlantis"})},
+			want:    "❌ No conozco esa provincia, usá `/region list` para ver las disponibles.",
+		},
+		{
+			name:    "show",
+			options: []holidaytest.Option{holidaytest.Subcommand("show", nil)},
+			want:    "📍 Tu provincia: **-**, provincia del servidor: **-**",
+		},
+		{
+			name:    "clear",
+			options: []holidaytest.Option{holidaytest.Subcommand("clear", nil)},
+			want:    "📍 Provincia borrada, solo se usan los feriados nacionales",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := newHarness(t, date(time.July, 1))
+			withRegions(t)
+
+			responses, err := h.Run(holidaytest.Interaction("region", test.options...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(responses) != 1 {
+				t.Fatalf("got %d responses, want 1", len(responses))
+			}
+			if got := responses[0].Content; got != test.want {
+				t.Errorf("got %q\nwant %q", got, test.want)
+			}
+			if !responses[0].Ephemeral {
+				t.Error("the response is public")
+			}
+			if got := settings.GetUser("user").Region; got != test.userRegion {
+				t.Errorf("user region %q, want %q", got, test.userRegion)
+			}
+			if got := settings.GetGuild("guild").Region; got != test.guildRegion {
+				t.Errorf("guild region %q, want %q", got, test.guildRegion)
+			}
+		})
+	}
+}
+
+// TestRegionalHolidays checks the holidays of the region set by the user, or
+// by the guild, are listed with the national ones until the region is cleared
+func TestRegionalHolidays(t *testing.T) {
+	admin := holidaytest.Permissions(discordgo.PermissionManageGuild)
+	for _, guild := range []bool{false, true} {
+		name := "user"
+		if guild {
+			name = "guild"
+		}
+		t.Run(name, func(t *testing.T) {
+			h := newHarness(t, date(time.July, 1))
+			withRegions(t)
+
+			run := func(command string, options ...holidaytest.Option) string {
+				t.Helper()
+				got, err := h.Reply(holidaytest.Interaction(command, options...))
+				if err != nil {
+					t.Fatal(err)
+				}
+				return got
+			}
+
+			run("region", admin, holidaytest.Subcommand("set", map[string]interface{}{"region": "cordoba", "guild": guild}))
+			if got := run("next-holiday"); !strings.Contains(got, "Día de Córdoba") {
+				t.Errorf("next holiday %q, want Día de Córdoba", got)
+			}
+			if got := run("holidays-of-month", holidaytest.Param("month", 7)); !strings.Contains(got, "Día de Córdoba") || !strings.Contains(got, "Día de la Independencia") {
+				t.Errorf("holidays of month %q, want both holidays of july", got)
+			}
+
+			run("region", admin, holidaytest.Subcommand("clear", map[string]interface{}{"guild": guild}))
+			if got := run("next-holiday"); strings.Contains(got, "Día de Córdoba") {
+				t.Errorf("next holiday %q, still the regional one", got)
+			}
+		})
+	}
+}
+
+// TestSettingsSaveFailure checks a settings change that cannot be written
+// answers with the save failure message, and changes nothing
+func TestSettingsSaveFailure(t *testing.T) {
+	h := newHarness(t, date(time.July, 1))
+	withRegions(t)
+
+	// a data directory that is a regular file cannot hold the settings
+	dataDir := filepath.Join(t.TempDir(), "data")
+	if err := os.WriteFile(dataDir, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	viper.Set("data-dir", dataDir)
+	settings.Reset()
+
+	admin := holidaytest.Permissions(discordgo.PermissionManageGuild)
+	interactions := map[string]*discordgo.InteractionCreate{
+		"region":         holidaytest.Interaction("region", holidaytest.Subcommand("set", map[string]interface{}{"region": "cordoba"})),
+		"custom holiday": holidaytest.Interaction("custom-holiday", admin, holidaytest.Subcommand("add", map[string]interface{}{"date": "07-02", "name": "Aniversario"})),
+		"privacy":        holidaytest.Interaction("privacy", admin, holidaytest.Param("private", true)),
+	}
+	for name, i := range interactions {
+		t.Run(name, func(t *testing.T) {
+			responses, err := h.Run(i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(responses) != 1 {
+				t.Fatalf("got %d responses, want 1", len(responses))
+			}
+			if got, want := responses[0].Content, "💾 No se pudieron guardar los cambios, probá de nuevo en un rato."; got != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+			if !responses[0].Ephemeral {
+				t.Error("the failure is public")
+			}
+		})
+	}
+}
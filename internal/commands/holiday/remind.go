@@ -0,0 +1,384 @@
+package holidays
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/FGasquez/alum-bot/internal/clock"
+	"github.com/FGasquez/alum-bot/internal/config"
+	"github.com/FGasquez/alum-bot/internal/helpers"
+	"github.com/FGasquez/alum-bot/internal/logging"
+	"github.com/FGasquez/alum-bot/internal/messages"
+	"github.com/FGasquez/alum-bot/internal/reminders"
+	"github.com/FGasquez/alum-bot/internal/responder"
+	"github.com/FGasquez/alum-bot/internal/types"
+	"github.com/bwmarrin/discordgo"
+	"github.com/sirupsen/logrus"
+)
+
+const RemindCommandName = "remind"
+
+var (
+	minReminderDays = float64(0)
+	maxReminderDays = float64(60)
+)
+
+var reminderDaysOption = &discordgo.ApplicationCommandOption{
+	Type:        discordgo.ApplicationCommandOptionInteger,
+	Name:        "days",
+	Description: "how many days before the holiday",
+	Required:    true,
+	MinValue:    &minReminderDays,
+	MaxValue:    maxReminderDays,
+}
+
+var reminderChannelOption = &discordgo.ApplicationCommandOption{
+	Type:        discordgo.ApplicationCommandOptionBoolean,
+	Name:        "in-channel",
+	Description: "mention you in this channel instead of sending a DM",
+	Required:    false,
+}
+
+var RemindCommand = discordgo.ApplicationCommand{
+	Name:        RemindCommandName,
+	Description: "Get reminded some days before a holiday",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        string(reminders.NextHoliday),
+			Description: "Remind you before every next holiday",
+			Options: []*discordgo.ApplicationCommandOption{
+				reminderDaysOption,
+				reminderChannelOption,
+				countryOption,
+				regionOption,
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        string(reminders.Holiday),
+			Description: "Remind you once before a specific holiday",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "date",
+					Description: "date of the holiday, yyyy-mm-dd",
+					Required:    true,
+				},
+				reminderDaysOption,
+				reminderChannelOption,
+				countryOption,
+				regionOption,
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        string(reminders.LongWeekend),
+			Description: "Remind you before every long weekend",
+			Options: []*discordgo.ApplicationCommandOption{
+				reminderDaysOption,
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "min-days",
+					Description: "minimum days off in a row (default: 3)",
+					Required:    false,
+					MinValue:    &minLongWeekendDays,
+				},
+				reminderChannelOption,
+				countryOption,
+				regionOption,
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "list",
+			Description: "List your reminders",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "cancel",
+			Description: "Cancel one of your reminders",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "id",
+					Description: "ID of the reminder, as shown by /remind list",
+					Required:    true,
+				},
+			},
+		},
+	},
+}
+
+func handleRemindCommand(ctx context.Context, r responder.Responder, i *discordgo.InteractionCreate) {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return
+	}
+	r.SetEphemeral(true)
+
+	userID := helpers.UserID(i)
+	params := helpers.GetParams(options)
+
+	switch options[0].Name {
+	case "list":
+		list := reminders.List(userID)
+		message := messages.MessageKeys.ReminderList
+		if len(list) == 0 {
+			message = messages.MessageKeys.NoReminders
+		}
+		r.Reply(messages.Render(ctx, message, messages.NewContext(ctx, func(c *messages.Context) {
+			c.Reminders = reminderList(list)
+		})))
+		return
+	case "cancel":
+		id, _ := params["id"].(string)
+		id = strings.TrimPrefix(strings.TrimSpace(id), "#")
+		cancelled, err := reminders.Cancel(userID, id)
+		if err != nil {
+			logging.FromContext(ctx).WithError(err).Error("Failed to cancel reminder")
+			r.ReplyError(messages.MessageKeys.FailedToSaveSettings)
+			return
+		}
+		message := messages.MessageKeys.ReminderNotFound
+		if cancelled {
+			message = messages.MessageKeys.ReminderCancelled
+		}
+		r.Reply(messages.Render(ctx, message, messages.NewContext(ctx, func(c *messages.Context) {
+			c.ID = id
+		})))
+		return
+	}
+
+	scope, err := ResolveScope(i, params)
+	if err != nil {
+		respondScopeError(ctx, r, err)
+		return
+	}
+
+	reminder := reminders.Reminder{
+		UserID:     userID,
+		GuildID:    i.GuildID,
+		Kind:       reminders.Kind(options[0].Name),
+		DaysBefore: helpers.IntParam(params[reminderDaysOption.Name]),
+		Country:    scope.Country,
+		Region:     scope.Region,
+	}
+	if inChannel, _ := params[reminderChannelOption.Name].(bool); inChannel && i.GuildID != "" {
+		reminder.ChannelID = i.ChannelID
+	}
+
+	switch reminder.Kind {
+	case reminders.NextHoliday:
+	case reminders.Holiday:
+		date, _ := params["date"].(string)
+		reminder.Date = strings.TrimSpace(date)
+		deferUnlessCached(r, scope, clock.Now().Year())
+
+		ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+		defer cancel()
+
+		holiday, err := upcomingHoliday(ctx, scope, reminder.Date)
+		if err != nil {
+			respondHolidaysError(ctx, r, err)
+			return
+		}
+		if holiday == nil {
+			r.Reply(messages.Render(ctx, messages.MessageKeys.NotAHoliday, messages.NewContext(ctx, func(c *messages.Context) {
+				c.Date = reminder.Date
+			})))
+			return
+		}
+	case reminders.LongWeekend:
+		reminder.MinDays = DefaultLongWeekendMinDays
+		if _, ok := params["min-days"]; ok {
+			reminder.MinDays = helpers.IntParam(params["min-days"])
+		}
+	default:
+		return
+	}
+
+	reminder, err = reminders.Add(reminder)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("Failed to save reminder")
+		r.ReplyError(messages.MessageKeys.FailedToSaveSettings)
+		return
+	}
+
+	r.Reply(messages.Render(ctx, messages.MessageKeys.ReminderSet, messages.NewContext(ctx, reminderContext(reminder))))
+}
+
+// upcomingHoliday returns the holiday on a yyyy-mm-dd date of the scope, nil
+// when the date is not a valid upcoming holiday
+func upcomingHoliday(ctx context.Context, scope Scope, date string) (*holidayOfDate, error) {
+	parsed, err := time.ParseInLocation(dateLayout, date, time.Local)
+	if err != nil || parsed.Before(startOfDay(clock.Now())) {
+		return nil, nil
+	}
+
+	index, err := GetIndex(ctx, scope, parsed.Year())
+	if err != nil {
+		return nil, err
+	}
+
+	holiday, ok := index.Get(date, clock.Now())
+	if !ok {
+		return nil, nil
+	}
+	return &holidayOfDate{Name: holiday.Name, Date: holiday.Date, DaysLeft: holiday.DaysLeftToHoliday}, nil
+}
+
+// holidayOfDate is the holiday a reminder is about
+type holidayOfDate struct {
+	Name     string
+	Date     string
+	DaysLeft int
+	// Length of the long weekend, only for long weekend reminders
+	Length int
+}
+
+// dueHoliday returns the holiday the reminder must be sent for now, if any.
+// finished reports that the reminder will never be sent again.
+func dueHoliday(ctx context.Context, reminder reminders.Reminder) (due *holidayOfDate, finished bool, err error) {
+	scope := Scope{Country: reminder.Country, Region: reminder.Region, GuildID: reminder.GuildID}
+
+	var holiday *holidayOfDate
+	switch reminder.Kind {
+	case reminders.NextHoliday:
+		daysLeft, next, _, err := DaysLeft(ctx, scope, true, false)
+		if err != nil {
+			return nil, false, err
+		}
+		holiday = &holidayOfDate{Name: next.Name, Date: next.Date, DaysLeft: daysLeft}
+	case reminders.Holiday:
+		holiday, err = upcomingHoliday(ctx, scope, reminder.Date)
+		if err != nil {
+			return nil, false, err
+		}
+		if holiday == nil {
+			return nil, true, nil
+		}
+	case reminders.LongWeekend:
+		longWeekend, err := GetNextLargeHoliday(ctx, scope, reminder.MinDays)
+		if err != nil || longWeekend == nil {
+			return nil, false, err
+		}
+		holiday = &holidayOfDate{
+			Name:     longWeekendName(longWeekend),
+			Date:     longWeekend.Start.Date,
+			DaysLeft: longWeekend.Start.DaysLeftToHoliday,
+			Length:   longWeekend.Length,
+		}
+	default:
+		return nil, true, nil
+	}
+
+	if holiday.DaysLeft < 0 || holiday.DaysLeft > reminder.DaysBefore {
+		return nil, false, nil
+	}
+	return holiday, false, nil
+}
+
+// MessageSender is the part of a discord session used to deliver reminders
+type MessageSender interface {
+	UserChannelCreate(recipientID string, options ...discordgo.RequestOption) (*discordgo.Channel, error)
+	ChannelMessageSend(channelID string, content string, options ...discordgo.RequestOption) (*discordgo.Message, error)
+}
+
+// RunReminders sends the due reminders every reminder-interval until ctx is cancelled
+func RunReminders(ctx context.Context, sender MessageSender) error {
+	SendDueReminders(ctx, sender)
+
+	ticker := time.NewTicker(config.GetReminderInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			SendDueReminders(ctx, sender)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// SendDueReminders sends every reminder due today that was not sent yet. The
+// reminders are read again when another instance changed them since the last tick.
+func SendDueReminders(ctx context.Context, sender MessageSender) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	for _, reminder := range reminders.List("") {
+		if ctx.Err() != nil {
+			return
+		}
+		log := logrus.WithField("reminder", reminder.ID)
+
+		holiday, finished, err := dueHoliday(ctx, reminder)
+		if err != nil {
+			log.WithError(err).Warn("Failed to check reminder")
+			continue
+		}
+		if finished {
+			log.Info("Removing finished reminder")
+			if err := reminders.Delete(reminder.ID); err != nil {
+				log.WithError(err).Error("Failed to remove reminder")
+			}
+			continue
+		}
+		if holiday == nil {
+			continue
+		}
+
+		// the reminder is marked before sending it, so a restart in between
+		// skips it instead of sending it twice
+		marked, err := reminders.MarkSent(reminder.ID, holiday.Date)
+		if err != nil {
+			log.WithError(err).Error("Failed to mark reminder as sent")
+			continue
+		}
+		if !marked {
+			continue
+		}
+
+		if err := deliverReminder(sender, reminder, holiday); err != nil {
+			log.WithError(err).Error("Failed to send reminder")
+		} else {
+			log.WithField("date", holiday.Date).Info("Sent reminder")
+		}
+
+		if reminder.Kind == reminders.Holiday {
+			if err := reminders.Delete(reminder.ID); err != nil {
+				log.WithError(err).Error("Failed to remove reminder")
+			}
+		}
+	}
+}
+
+func deliverReminder(sender MessageSender, reminder reminders.Reminder, holiday *holidayOfDate) error {
+	// the reminders keep no locale, only the template of their guild applies
+	ctx := messages.WithScope(context.Background(), messages.Scope{GuildID: reminder.GuildID, UserID: reminder.UserID})
+	reminded := types.ParsedHolidays{Name: holiday.Name, Date: holiday.Date}
+	values := messages.NewContext(ctx,
+		reminderContext(reminder),
+		messages.Holiday(reminded, holiday.DaysLeft),
+		Describe(Scope{Country: reminder.Country}, reminded),
+	)
+	values.Length = holiday.Length
+
+	channelID := reminder.ChannelID
+	if channelID != "" {
+		values.Mention = "<@" + reminder.UserID + ">"
+	} else {
+		channel, err := sender.UserChannelCreate(reminder.UserID)
+		if err != nil {
+			return err
+		}
+		channelID = channel.ID
+	}
+
+	content := messages.Render(ctx, messages.MessageKeys.Reminder, values)
+	_, err := sender.ChannelMessageSend(channelID, content)
+	return err
+}
@@ -0,0 +1,291 @@
+package holidays_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	holidays "github.com/FGasquez/alum-bot/internal/commands/holiday"
+	"github.com/FGasquez/alum-bot/internal/commands/holiday/holidaytest"
+	"github.com/FGasquez/alum-bot/internal/reminders"
+	"github.com/FGasquez/alum-bot/internal/types"
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestRemindCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []holidaytest.Option
+		want    string
+		// reminder is the reminder stored, none when nil
+		reminder *reminders.Reminder
+	}{
+		{
+			name:     "next holiday",
+			options:  []holidaytest.Option{holidaytest.Subcommand("next", map[string]interface{}{"days": 3})},
+			want:     "⏰ Recordatorio **#1** creado: te aviso 3 días antes de cada próximo feriado, por mensaje privado",
+			reminder: &reminders.Reminder{GuildID: "guild", Kind: reminders.NextHoliday, DaysBefore: 3},
+		},
+		{
+			name: "holiday in this channel",
+			options: []holidaytest.Option{
+				holidaytest.Channel("channel"),
+				holidaytest.Subcommand("holiday", map[string]interface{}{"date": " 2025-07-09 ", "days": 2, "in-channel": true}),
+			},
+			want:     "⏰ Recordatorio **#1** creado: te aviso 2 días antes del feriado del **2025-07-09**, en este canal",
+			reminder: &reminders.Reminder{GuildID: "guild", Kind: reminders.Holiday, DaysBefore: 2, Date: "2025-07-09", ChannelID: "channel"},
+		},
+		{
+			name: "in channel from a DM",
+			options: []holidaytest.Option{
+				holidaytest.Guild(""),
+				holidaytest.Channel("dm"),
+				holidaytest.Subcommand("holiday", map[string]interface{}{"date": "2025-07-09", "days": 2, "in-channel": true}),
+			},
+			want:     "⏰ Recordatorio **#1** creado: te aviso 2 días antes del feriado del **2025-07-09**, por mensaje privado",
+			reminder: &reminders.Reminder{Kind: reminders.Holiday, DaysBefore: 2, Date: "2025-07-09"},
+		},
+		{
+			name:    "not a holiday",
+			options: []holidaytest.Option{holidaytest.Subcommand("holiday", map[string]interface{}{"date": "2025-07-10", "days": 2})},
+			want:    "❌ El **2025-07-10** no es un feriado próximo, usá aaaa-mm-dd",
+		},
+		{
+			name:    "past holiday",
+			options: []holidaytest.Option{holidaytest.Subcommand("holiday", map[string]interface{}{"date": "2025-05-25", "days": 2})},
+			want:    "❌ El **2025-05-25** no es un feriado próximo, usá aaaa-mm-dd",
+		},
+		{
+			name:    "not a date",
+			options: []holidaytest.Option{holidaytest.Subcommand("holiday", map[string]interface{}{"date": "9/7", "days": 2})},
+			want:    "❌ El **9/7** no es un feriado próximo, usá aaaa-mm-dd",
+		},
+		{
+			name:     "long weekend",
+			options:  []holidaytest.Option{holidaytest.Subcommand("long-weekend", map[string]interface{}{"days": 5})},
+			want:     "⏰ Recordatorio **#1** creado: te aviso 5 días antes de cada finde largo de 3 días o más, por mensaje privado",
+			reminder: &reminders.Reminder{GuildID: "guild", Kind: reminders.LongWeekend, DaysBefore: 5, MinDays: 3},
+		},
+		{
+			name:     "long weekend of four days",
+			options:  []holidaytest.Option{holidaytest.Subcommand("long-weekend", map[string]interface{}{"days": 5, "min-days": 4})},
+			want:     "⏰ Recordatorio **#1** creado: te aviso 5 días antes de cada finde largo de 4 días o más, por mensaje privado",
+			reminder: &reminders.Reminder{GuildID: "guild", Kind: reminders.LongWeekend, DaysBefore: 5, MinDays: 4},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := newHarness(t, date(time.July, 1))
+			responses, err := h.Run(holidaytest.Interaction("remind", test.options...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(responses) != 1 {
+				t.Fatalf("got %d responses, want 1", len(responses))
+			}
+			if got := responses[0].Content; got != test.want {
+				t.Errorf("got %q\nwant %q", got, test.want)
+			}
+			if !responses[0].Ephemeral {
+				t.Error("the response is public")
+			}
+
+			list := reminders.List("")
+			if test.reminder == nil {
+				if len(list) != 0 {
+					t.Errorf("stored %+v", list)
+				}
+				return
+			}
+			if len(list) != 1 {
+				t.Fatalf("stored %d reminders, want 1", len(list))
+			}
+			got := list[0]
+			want := *test.reminder
+			want.ID, want.UserID, want.Country = "1", "user", "AR"
+			got.CreatedAt = time.Time{}
+			if got.ID != want.ID || got.UserID != want.UserID || got.GuildID != want.GuildID || got.ChannelID != want.ChannelID ||
+				got.Kind != want.Kind || got.DaysBefore != want.DaysBefore || got.Date != want.Date ||
+				got.MinDays != want.MinDays || got.Country != want.Country || got.Region != want.Region {
+				t.Errorf("stored %+v\nwant %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestRemindListAndCancel(t *testing.T) {
+	h := newHarness(t, date(time.July, 1))
+	for _, user := range []string{"user", "other"} {
+		_, err := h.Reply(holidaytest.Interaction("remind", holidaytest.User(user),
+			holidaytest.Subcommand("next", map[string]interface{}{"days": 3})))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		user string
+		id   string
+		want string
+	}{
+		{name: "of another user", user: "user", id: "2", want: "No tenés ningún recordatorio **#2**"},
+		{name: "own with hash", user: "user", id: " #1", want: "🗑️ Recordatorio **#1** cancelado"},
+		{name: "already cancelled", user: "user", id: "1", want: "No tenés ningún recordatorio **#1**"},
+	}
+	for _, test := range tests {
+		got, err := h.Reply(holidaytest.Interaction("remind", holidaytest.User(test.user),
+			holidaytest.Subcommand("cancel", map[string]interface{}{"id": test.id})))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+
+	got, err := h.Reply(holidaytest.Interaction("remind", holidaytest.Subcommand("list", nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "No tenés recordatorios, creá uno con `/remind`"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if list := reminders.List("other"); len(list) != 1 {
+		t.Errorf("the reminder of the other user was cancelled")
+	}
+}
+
+// sender records the reminders delivered, failing every send when err is set
+type sender struct {
+	err  error
+	sent []string
+}
+
+func (s *sender) UserChannelCreate(recipientID string, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	return &discordgo.Channel{ID: "dm-" + recipientID}, nil
+}
+
+func (s *sender) ChannelMessageSend(channelID string, content string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.sent = append(s.sent, channelID+": "+content)
+	return &discordgo.Message{}, nil
+}
+
+func addReminder(t *testing.T, reminder reminders.Reminder) reminders.Reminder {
+	t.Helper()
+	reminder.UserID = "user"
+	reminder.Country = "AR"
+	added, err := reminders.Add(reminder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return added
+}
+
+// TestSendDueReminders runs two ticks of the scheduler, the reminders keep no
+// locale so they are rendered in the default one
+func TestSendDueReminders(t *testing.T) {
+	tests := []struct {
+		name     string
+		now      time.Time
+		reminder reminders.Reminder
+		want     []string
+		// kept reports the reminder is still stored after sending
+		kept bool
+	}{
+		{
+			name:     "next holiday due",
+			now:      date(time.July, 6),
+			reminder: reminders.Reminder{Kind: reminders.NextHoliday, DaysBefore: 3},
+			want:     []string{"dm-user: **Día de la Independencia** is in **3** days (2025-07-09)"},
+			kept:     true,
+		},
+		{
+			name:     "next holiday not due yet",
+			now:      date(time.July, 1),
+			reminder: reminders.Reminder{Kind: reminders.NextHoliday, DaysBefore: 3},
+			kept:     true,
+		},
+		{
+			name:     "holiday in a channel",
+			now:      date(time.July, 9),
+			reminder: reminders.Reminder{Kind: reminders.Holiday, DaysBefore: 2, Date: "2025-07-09", ChannelID: "channel"},
+			want:     []string{"channel: <@user> **Día de la Independencia** is today!"},
+		},
+		{
+			name:     "past holiday",
+			now:      date(time.July, 10),
+			reminder: reminders.Reminder{Kind: reminders.Holiday, DaysBefore: 2, Date: "2025-07-09"},
+		},
+		{
+			name:     "holiday of next year",
+			now:      date(time.December, 26),
+			reminder: reminders.Reminder{Kind: reminders.Holiday, DaysBefore: 7, Date: "2026-01-01"},
+			want:     []string{"dm-user: **Año Nuevo** is in **6** days (2026-01-01)"},
+		},
+		{
+			name:     "long weekend",
+			now:      date(time.August, 12),
+			reminder: reminders.Reminder{Kind: reminders.LongWeekend, DaysBefore: 3, MinDays: 3},
+			want:     []string{"dm-user: **Paso a la Inmortalidad del General José de San Martín** is in **3** days (2025-08-15), a long weekend of 3 days"},
+			kept:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := newHarness(t, test.now)
+			h.Source("AR", holidaytest.Source{ByYear: map[int][]types.Holiday{
+				2025: argentina2025,
+				2026: {{Date: "2026-01-01", Type: "inamovible", Name: "Año Nuevo"}},
+			}})
+			addReminder(t, test.reminder)
+
+			var s sender
+			holidays.SendDueReminders(context.Background(), &s)
+			// a second tick on the same day sends nothing again
+			holidays.SendDueReminders(context.Background(), &s)
+
+			if len(s.sent) != len(test.want) {
+				t.Fatalf("sent %q, want %q", s.sent, test.want)
+			}
+			for n := range test.want {
+				if s.sent[n] != test.want[n] {
+					t.Errorf("sent %q\nwant %q", s.sent[n], test.want[n])
+				}
+			}
+			if kept := len(reminders.List("")) == 1; kept != test.kept {
+				t.Errorf("kept %t, want %t", kept, test.kept)
+			}
+		})
+	}
+}
+
+// TestSendDueRemindersMarksBeforeSending checks a reminder whose delivery
+// fails is not sent again, as it happens when the bot restarts mid send
+func TestSendDueRemindersMarksBeforeSending(t *testing.T) {
+	newHarness(t, date(time.July, 6))
+	reminder := addReminder(t, reminders.Reminder{Kind: reminders.NextHoliday, DaysBefore: 3})
+
+	failing := sender{err: errors.New("discord is down")}
+	holidays.SendDueReminders(context.Background(), &failing)
+
+	list := reminders.List("")
+	if len(list) != 1 || len(list[0].Sent) != 1 || list[0].Sent[0] != "2025-07-09" {
+		t.Fatalf("got %+v, want the reminder marked as sent for 2025-07-09", list)
+	}
+
+	var s sender
+	holidays.SendDueReminders(context.Background(), &s)
+	if len(s.sent) != 0 {
+		t.Errorf("sent %q again", s.sent)
+	}
+	if marked, err := reminders.MarkSent(reminder.ID, "2025-07-09"); err != nil || marked {
+		t.Errorf("marked again: %t, %v", marked, err)
+	}
+}
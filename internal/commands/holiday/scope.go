@@ -0,0 +1,226 @@
+package holidays
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/FGasquez/alum-bot/internal/config"
+	"github.com/FGasquez/alum-bot/internal/helpers"
+	"github.com/FGasquez/alum-bot/internal/holidayinfo"
+	"github.com/FGasquez/alum-bot/internal/logging"
+	"github.com/FGasquez/alum-bot/internal/messages"
+	"github.com/FGasquez/alum-bot/internal/regions"
+	"github.com/FGasquez/alum-bot/internal/responder"
+	"github.com/FGasquez/alum-bot/internal/settings"
+	"github.com/FGasquez/alum-bot/internal/sources"
+	"github.com/FGasquez/alum-bot/internal/types"
+	"github.com/bwmarrin/discordgo"
+	"github.com/sirupsen/logrus"
+)
+
+// Scope selects the country of the holidays and the holiday sets layered on top of them
+type Scope struct {
+	Country string
+	Region  string
+	GuildID string
+}
+
+var (
+	errUnknownCountry = errors.New("unknown country")
+	errUnknownRegion  = errors.New("unknown region")
+)
+
+var countryOption = &discordgo.ApplicationCommandOption{
+	Type:         discordgo.ApplicationCommandOptionString,
+	Name:         "country",
+	Description:  "country code of the holidays (default: your country setting)",
+	Required:     false,
+	Autocomplete: true,
+}
+
+var regionOption = &discordgo.ApplicationCommandOption{
+	Type:         discordgo.ApplicationCommandOptionString,
+	Name:         "region",
+	Description:  "province whose holidays are included (default: your region setting)",
+	Required:     false,
+	Autocomplete: true,
+}
+
+// ResolveScope returns the scope of an interaction. The command options take
+// precedence over the user settings, and those over the guild settings. A
+// region option without a country option also selects the region country.
+func ResolveScope(i *discordgo.InteractionCreate, params map[string]interface{}) (Scope, error) {
+	scope := GuildScope(i.GuildID)
+
+	user := settings.GetUser(helpers.UserID(i))
+	if user.Country != "" {
+		scope.Country = user.Country
+	}
+	if user.Region != "" {
+		scope.Region = user.Region
+	}
+
+	if region, ok := params[regionOption.Name].(string); ok && region != "" {
+		r, err := regions.Get(region)
+		if err != nil {
+			return Scope{}, fmt.Errorf("%w: %v", errUnknownRegion, err)
+		}
+		scope.Region = r.Code
+		scope.Country = r.Country
+	}
+
+	if country, ok := params[countryOption.Name].(string); ok && country != "" {
+		scope.Country = country
+	}
+
+	scope.Country = sources.NormalizeCountry(scope.Country)
+	if _, err := sources.Get(scope.Country); err != nil {
+		return Scope{}, fmt.Errorf("%w: %v", errUnknownCountry, err)
+	}
+
+	return scope, nil
+}
+
+// GuildScope returns the scope configured for a guild, used when there is no user to ask
+func GuildScope(guildID string) Scope {
+	scope := Scope{Country: config.GetDefaultCountry()}
+	if guildID == "" {
+		return scope
+	}
+
+	guild := settings.GetGuild(guildID)
+	if guild.Country != "" {
+		scope.Country = guild.Country
+	}
+	scope.Region = guild.Region
+	scope.GuildID = guildID
+	return scope
+}
+
+// country returns the country of the scope, the default one when not set
+func (sc Scope) country() string {
+	if sc.Country == "" {
+		return sources.NormalizeCountry(config.GetDefaultCountry())
+	}
+	return sources.NormalizeCountry(sc.Country)
+}
+
+// holidays returns the extra holidays of the scope for the given year
+func (sc Scope) holidays(year int) []types.Holiday {
+	var holidays []types.Holiday
+
+	if sc.Region != "" {
+		region, err := regions.Get(sc.Region)
+		switch {
+		case err != nil:
+			logrus.WithError(err).Warn("Failed to load region holidays, using national holidays only")
+		case region.Country != sc.country():
+			logrus.WithField("region", region.Code).Debug("Region belongs to another country, using national holidays only")
+		default:
+			holidays = append(holidays, region.HolidaysFor(year)...)
+		}
+	}
+
+	if sc.GuildID != "" {
+		for _, custom := range settings.GetGuild(sc.GuildID).CustomHolidays {
+			if date, ok := helpers.DateForYear(custom.Date, year); ok {
+				holidays = append(holidays, types.Holiday{
+					Date: date,
+					Type: types.Custom,
+					Name: custom.Name,
+				})
+			}
+		}
+	}
+
+	return holidays
+}
+
+// AutocompleteHandlers suggests the available countries, regions, holidays and message keys for the options naming them
+var AutocompleteHandlers = func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	focused := focusedOption(i.ApplicationCommandData().Options)
+	if focused == nil {
+		return
+	}
+	typed := strings.ToLower(focused.StringValue())
+
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	switch focused.Name {
+	case countryOption.Name:
+		for _, country := range sources.Countries() {
+			if strings.Contains(strings.ToLower(country), typed) {
+				choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+					Name:  country,
+					Value: country,
+				})
+			}
+		}
+	case regionOption.Name:
+		for _, region := range regions.List() {
+			if !strings.Contains(strings.ToLower(region.Name), typed) && !strings.Contains(region.Code, typed) {
+				continue
+			}
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+				Name:  region.Name,
+				Value: region.Code,
+			})
+		}
+	case holidayNameOption.Name:
+		for _, info := range holidayinfo.Search(typed) {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+				Name:  info.Name,
+				Value: info.Name,
+			})
+		}
+	case messageKeyOption.Name:
+		for _, key := range messages.Keys() {
+			if strings.Contains(strings.ToLower(key), typed) {
+				choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+					Name:  key,
+					Value: key,
+				})
+			}
+		}
+	}
+
+	// discord rejects autocomplete responses with more than 25 choices
+	if len(choices) > 25 {
+		choices = choices[:25]
+	}
+
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{
+			Choices: choices,
+		},
+	})
+	if err != nil {
+		logrus.WithError(err).Error("Failed to respond autocomplete")
+	}
+}
+
+// respondScopeError tells the user that the country or region asked for does not exist
+func respondScopeError(ctx context.Context, r responder.Responder, err error) {
+	logging.FromContext(ctx).WithError(err).Warn("Failed to resolve scope")
+
+	message := messages.MessageKeys.UnknownRegion
+	if errors.Is(err, errUnknownCountry) {
+		message = messages.MessageKeys.UnknownCountry
+	}
+	r.ReplyEphemeral(messages.Render(ctx, message, messages.NewContext(ctx)))
+}
+
+// focusedOption returns the option the user is typing in, looking into subcommands
+func focusedOption(options []*discordgo.ApplicationCommandInteractionDataOption) *discordgo.ApplicationCommandInteractionDataOption {
+	for _, option := range options {
+		if option.Focused {
+			return option
+		}
+		if focused := focusedOption(option.Options); focused != nil {
+			return focused
+		}
+	}
+	return nil
+}
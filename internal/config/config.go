@@ -3,24 +3,219 @@ package config
 import (
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
+// Kind is the type of the value of a setting
+type Kind int
+
+const (
+	String Kind = iota
+	List
+	Duration
+	Int
+)
+
+// Setting is a configuration key, read from its flag, its environment
+// variable, the config file or its default, in that order
+type Setting struct {
+	Key     string
+	Env     string
+	Default string
+	Kind    Kind
+	// Secret settings are redacted when printed
+	Secret bool
+}
+
+// Settings are all the configuration keys, named like their flags
+var Settings = []Setting{
+	{Key: "token", Env: "DISCORD_TOKEN", Secret: true},
+	{Key: "token-file", Env: "DISCORD_TOKEN_FILE"},
+	{Key: "test-guilds", Env: "TEST_GUILD_ID", Kind: List},
+	{Key: "messages-file", Env: "MESSAGES_FILE"},
+	{Key: "messages-dir", Env: "MESSAGES_DIR", Default: "messages"},
+	{Key: "locale", Env: "LOCALE", Default: "en"},
+	{Key: "timezone", Env: "TZ"},
+	{Key: "data-dir", Env: "DATA_DIR", Default: "data"},
+	{Key: "regions-dir", Env: "REGIONS_DIR", Default: "regions"},
+	{Key: "status-guild", Env: "STATUS_GUILD_ID"},
+	{Key: "sources-dir", Env: "SOURCES_DIR", Default: "holidays"},
+	{Key: "default-country", Env: "DEFAULT_COUNTRY", Default: "AR"},
+	{Key: "api-timeout", Env: "API_TIMEOUT", Default: "5s", Kind: Duration},
+	{Key: "api-retries", Env: "API_RETRIES", Default: "2", Kind: Int},
+	{Key: "api-backoff", Env: "API_BACKOFF", Default: "500ms", Kind: Duration},
+	{Key: "cache-dir", Env: "CACHE_DIR", Default: os.TempDir()},
+	{Key: "cache-ttl", Env: "CACHE_TTL", Default: "24h", Kind: Duration},
+	{Key: "cache-refresh-before", Env: "CACHE_REFRESH_BEFORE", Default: "1h", Kind: Duration},
+	{Key: "status-messages", Env: "STATUS_MESSAGES", Default: "activityStatus", Kind: List},
+	{Key: "status-interval", Env: "STATUS_INTERVAL", Default: "10m", Kind: Duration},
+	{Key: "status-activity-type", Env: "STATUS_ACTIVITY_TYPE", Default: "custom"},
+	{Key: "reminder-interval", Env: "REMINDER_INTERVAL", Default: "15m", Kind: Duration},
+	{Key: "http-addr", Env: "HTTP_ADDR"},
+	{Key: "shutdown-timeout", Env: "SHUTDOWN_TIMEOUT", Default: "10s", Kind: Duration},
+	{Key: "shard-id", Env: "SHARD_ID", Default: "0", Kind: Int},
+	{Key: "shard-count", Env: "SHARD_COUNT", Default: "1"},
+	{Key: "leader-lock", Env: "LEADER_LOCK"},
+	{Key: "leader-lease", Env: "LEADER_LEASE", Default: "30s", Kind: Duration},
+	{Key: "log-level", Env: "LOG_LEVEL", Default: "debug"},
+	{Key: "log-format", Env: "LOG_FORMAT", Default: "json"},
+}
+
 func init() {
-	viper.SetDefault("token", os.Getenv("DISCORD_TOKEN"))
-	viper.SetDefault("test-guilds", strings.Split(os.Getenv("TEST_GUILD_ID"), ","))
-	viper.SetDefault("messages-file", os.Getenv("MESSAGES_FILE"))
+	register()
 }
 
-func GetToken() string {
-	return viper.GetString("token")
+// register sets the default and the environment variable of every setting
+func register() {
+	for _, setting := range Settings {
+		viper.SetDefault(setting.Key, setting.Default)
+		viper.BindEnv(setting.Key, setting.Env)
+	}
 }
 
 func GetTestGuilds() []string {
-	return viper.GetStringSlice("test-guilds")
+	return list("test-guilds")
 }
 
 func GetMessagesPath() string {
 	return viper.GetString("messages-file")
 }
+
+func GetDataDir() string {
+	return viper.GetString("data-dir")
+}
+
+func GetRegionsDir() string {
+	return viper.GetString("regions-dir")
+}
+
+// GetStatusGuild returns the guild whose custom holidays and region are used
+// for the activity status, the first test guild when not set
+func GetStatusGuild() string {
+	if guild := viper.GetString("status-guild"); guild != "" {
+		return guild
+	}
+	if guilds := GetTestGuilds(); len(guilds) > 0 {
+		return guilds[0]
+	}
+	return ""
+}
+
+func GetSourcesDir() string {
+	return viper.GetString("sources-dir")
+}
+
+func GetDefaultCountry() string {
+	return viper.GetString("default-country")
+}
+
+func GetAPITimeout() time.Duration {
+	return viper.GetDuration("api-timeout")
+}
+
+func GetAPIRetries() int {
+	return viper.GetInt("api-retries")
+}
+
+func GetAPIBackoff() time.Duration {
+	return viper.GetDuration("api-backoff")
+}
+
+func GetCacheDir() string {
+	return viper.GetString("cache-dir")
+}
+
+func GetCacheTTL() time.Duration {
+	return viper.GetDuration("cache-ttl")
+}
+
+func GetCacheRefreshBefore() time.Duration {
+	return viper.GetDuration("cache-refresh-before")
+}
+
+// GetStatusMessages returns the message keys the activity status rotates through
+func GetStatusMessages() []string {
+	return list("status-messages")
+}
+
+func GetStatusInterval() time.Duration {
+	return viper.GetDuration("status-interval")
+}
+
+func GetStatusActivityType() string {
+	return strings.ToLower(viper.GetString("status-activity-type"))
+}
+
+// GetHTTPAddr returns the address of the HTTP server, empty when disabled
+func GetHTTPAddr() string {
+	return viper.GetString("http-addr")
+}
+
+// GetReminderInterval returns how often the reminders are checked
+func GetReminderInterval() time.Duration {
+	return viper.GetDuration("reminder-interval")
+}
+
+func GetMessagesDir() string {
+	return viper.GetString("messages-dir")
+}
+
+func GetLocale() string {
+	return viper.GetString("locale")
+}
+
+// GetTimezone returns the name of the timezone of the dates, empty for the system one
+func GetTimezone() string {
+	return viper.GetString("timezone")
+}
+
+// GetShutdownTimeout returns how long the commands in progress are waited for on shutdown
+func GetShutdownTimeout() time.Duration {
+	return viper.GetDuration("shutdown-timeout")
+}
+
+func GetShardID() int {
+	return viper.GetInt("shard-id")
+}
+
+// GetShardCount returns the number of shards, 0 when Discord recommends it
+func GetShardCount() int {
+	if strings.EqualFold(viper.GetString("shard-count"), "auto") {
+		return 0
+	}
+	return viper.GetInt("shard-count")
+}
+
+// GetLeaderLock returns the lock file shared by the instances to elect the one
+// sending the reminders, empty when there is a single instance
+func GetLeaderLock() string {
+	return viper.GetString("leader-lock")
+}
+
+func GetLeaderLease() time.Duration {
+	return viper.GetDuration("leader-lease")
+}
+
+func GetLogLevel() string {
+	return viper.GetString("log-level")
+}
+
+func GetLogFormat() string {
+	return viper.GetString("log-format")
+}
+
+// list returns the values of a list setting, which may also be given as a
+// comma separated string, without the empty ones
+func list(key string) []string {
+	var values []string
+	for _, value := range viper.GetStringSlice(key) {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// reset starts every test from the defaults, without any setting of the
+// environment leaking in
+func reset(t *testing.T) {
+	t.Helper()
+	for _, setting := range Settings {
+		if value, ok := os.LookupEnv(setting.Env); ok {
+			t.Setenv(setting.Env, value)
+			os.Unsetenv(setting.Env)
+		}
+	}
+	viper.Reset()
+	register()
+	t.Cleanup(func() {
+		viper.Reset()
+		register()
+	})
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		// want are the keys reported, none when valid
+		want []string
+	}{
+		{name: "defaults"},
+		{name: "valid values", settings: map[string]interface{}{"locale": "es-AR", "timezone": "America/Argentina/Buenos_Aires", "http-addr": ":8080", "shard-count": "auto", "log-format": "text"}},
+		{name: "not a duration", settings: map[string]interface{}{"api-timeout": "soon"}, want: []string{"api-timeout"}},
+		{name: "negative duration", settings: map[string]interface{}{"api-backoff": "-1s"}, want: []string{"api-backoff"}},
+		{name: "not a number", settings: map[string]interface{}{"api-retries": "many"}, want: []string{"api-retries"}},
+		{name: "negative number", settings: map[string]interface{}{"api-retries": "-1"}, want: []string{"api-retries"}},
+		{name: "zero interval", settings: map[string]interface{}{"reminder-interval": "0s"}, want: []string{"reminder-interval"}},
+		{name: "refresh not before the ttl", settings: map[string]interface{}{"cache-ttl": "1h", "cache-refresh-before": "1h"}, want: []string{"cache-refresh-before"}},
+		{name: "token and token file", settings: map[string]interface{}{"token": "x", "token-file": "/run/secrets/token"}, want: []string{"token-file"}},
+		{name: "missing token file", settings: map[string]interface{}{"token-file": "/nonexistent/token"}, want: []string{"token-file"}},
+		{name: "no shards", settings: map[string]interface{}{"shard-count": "0"}, want: []string{"shard-count"}},
+		{name: "shard out of range", settings: map[string]interface{}{"shard-count": "2", "shard-id": "2"}, want: []string{"shard-id"}},
+		{name: "no default country", settings: map[string]interface{}{"default-country": ""}, want: []string{"default-country"}},
+		{name: "bad locale", settings: map[string]interface{}{"locale": "spanish"}, want: []string{"locale"}},
+		{name: "unknown timezone", settings: map[string]interface{}{"timezone": "Mars/Olympus"}, want: []string{"timezone"}},
+		{name: "missing messages file", settings: map[string]interface{}{"messages-file": "/nonexistent/messages.yaml"}, want: []string{"messages-file"}},
+		{name: "bad http address", settings: map[string]interface{}{"http-addr": "8080"}, want: []string{"http-addr"}},
+		{name: "bad activity type", settings: map[string]interface{}{"status-activity-type": "sleeping"}, want: []string{"status-activity-type"}},
+		{name: "bad log level", settings: map[string]interface{}{"log-level": "loud"}, want: []string{"log-level"}},
+		{name: "bad log format", settings: map[string]interface{}{"log-format": "xml"}, want: []string{"log-format"}},
+		{name: "every error", settings: map[string]interface{}{"locale": "spanish", "log-format": "xml", "api-retries": "many"}, want: []string{"locale", "log-format", "api-retries"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reset(t)
+			for key, value := range test.settings {
+				viper.Set(key, value)
+			}
+
+			err := Validate()
+			if len(test.want) == 0 {
+				if err != nil {
+					t.Fatalf("got %v, want no error", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("got no error, want %v", test.want)
+			}
+			for _, key := range test.want {
+				if !strings.Contains(err.Error(), "\n"+key+": ") {
+					t.Errorf("%q does not report %s", err, key)
+				}
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		locale  string
+		wantErr string
+	}{
+		{name: "yaml", file: "config.yaml", content: "locale: es\ndata-dir: /data\n", locale: "es"},
+		{name: "toml", file: "config.toml", content: "locale = \"pt\"\n", locale: "pt"},
+		{name: "unknown settings", file: "config.yaml", content: "locale: es\nlocal: es\ncolour: red\n", wantErr: "unknown settings: colour, local"},
+		{name: "malformed", file: "config.yaml", content: "locale: [es\n", wantErr: "reading config file"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reset(t)
+			err := Load(writeFile(t, test.file, test.content))
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("got %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := GetLocale(); got != test.locale {
+				t.Errorf("locale %q, want %q", got, test.locale)
+			}
+		})
+	}
+
+	t.Run("no file", func(t *testing.T) {
+		reset(t)
+		if err := Load(""); err != nil {
+			t.Fatal(err)
+		}
+		if got := GetLocale(); got != "en" {
+			t.Errorf("locale %q, want the default en", got)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		reset(t)
+		if err := Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+			t.Error("got no error")
+		}
+	})
+}
+
+// TestPrecedence checks a flag beats its environment variable, which beats
+// the config file, which beats the default
+func TestPrecedence(t *testing.T) {
+	tests := []struct {
+		name string
+		file bool
+		env  bool
+		flag bool
+		want string
+	}{
+		{name: "default", want: "en"},
+		{name: "file", file: true, want: "fr"},
+		{name: "environment over file", file: true, env: true, want: "pt"},
+		{name: "flag over environment", file: true, env: true, flag: true, want: "de"},
+		{name: "flag over file", file: true, flag: true, want: "de"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reset(t)
+			flags := pflag.NewFlagSet("alum-bot", pflag.ContinueOnError)
+			flags.String("locale", "", "")
+			if err := viper.BindPFlags(flags); err != nil {
+				t.Fatal(err)
+			}
+
+			if test.file {
+				if err := Load(writeFile(t, "config.yaml", "locale: fr\n")); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if test.env {
+				t.Setenv("LOCALE", "pt")
+			}
+			var args []string
+			if test.flag {
+				args = []string{"--locale", "de"}
+			}
+			if err := flags.Parse(args); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := GetLocale(); got != test.want {
+				t.Errorf("locale %q, want %q", got, test.want)
+			}
+		})
+	}
+}
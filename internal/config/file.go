@@ -0,0 +1,205 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+const redacted = "<redacted>"
+
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Z]{2})?$`)
+
+// Load reads a YAML or TOML config file, chosen by its extension, with the
+// settings keyed like their flags. Unknown keys are rejected.
+func Load(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	file := viper.New()
+	file.SetConfigFile(path)
+	if err := file.ReadInConfig(); err != nil {
+		return fmt.Errorf("reading config file %s: %w", path, err)
+	}
+
+	known := map[string]bool{}
+	for _, setting := range Settings {
+		known[setting.Key] = true
+	}
+	var unknown []string
+	for _, key := range file.AllKeys() {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("config file %s: unknown settings: %s", path, strings.Join(unknown, ", "))
+	}
+
+	viper.SetConfigFile(path)
+	if err := viper.MergeConfigMap(file.AllSettings()); err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate checks the effective configuration, reporting every invalid setting
+func Validate() error {
+	var errs []error
+	invalid := func(key string, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	for _, setting := range Settings {
+		value := viper.GetString(setting.Key)
+		switch setting.Kind {
+		case Duration:
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				invalid(setting.Key, "%q is not a duration, e.g. 30s or 10m", value)
+			} else if d < 0 {
+				invalid(setting.Key, "must not be negative")
+			}
+		case Int:
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				invalid(setting.Key, "%q is not a number", value)
+			} else if n < 0 {
+				invalid(setting.Key, "must not be negative")
+			}
+		}
+	}
+
+	for _, key := range []string{"api-timeout", "cache-ttl", "status-interval", "reminder-interval", "shutdown-timeout", "leader-lease"} {
+		if d, err := time.ParseDuration(viper.GetString(key)); err == nil && d == 0 {
+			invalid(key, "must be greater than zero")
+		}
+	}
+	if GetCacheTTL() > 0 && GetCacheRefreshBefore() >= GetCacheTTL() {
+		invalid("cache-refresh-before", "must be shorter than cache-ttl (%s)", GetCacheTTL())
+	}
+
+	if viper.GetString("token") != "" && GetTokenFile() != "" {
+		invalid("token-file", "set either token or token-file, not both")
+	} else if path := GetTokenFile(); path != "" {
+		if _, err := os.Stat(path); err != nil {
+			invalid("token-file", "%v", err)
+		}
+	}
+	if count := viper.GetString("shard-count"); !strings.EqualFold(count, "auto") {
+		if n, err := strconv.Atoi(count); err != nil || n < 1 {
+			invalid("shard-count", "%q is not auto or a number of shards", count)
+		} else if GetShardID() >= n {
+			invalid("shard-id", "must be lower than shard-count (%d)", n)
+		}
+	}
+	if GetDefaultCountry() == "" {
+		invalid("default-country", "must not be empty")
+	}
+	if !localePattern.MatchString(GetLocale()) {
+		invalid("locale", "%q is not a locale, e.g. en or es", GetLocale())
+	}
+	if tz := GetTimezone(); tz != "" {
+		if _, err := time.LoadLocation(tz); err != nil {
+			invalid("timezone", "unknown timezone %q", tz)
+		}
+	}
+	if path := GetMessagesPath(); path != "" {
+		if _, err := os.Stat(path); err != nil {
+			invalid("messages-file", "%v", err)
+		}
+	}
+	if addr := GetHTTPAddr(); addr != "" {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			invalid("http-addr", "%q is not an address, e.g. :8080", addr)
+		}
+	}
+	switch GetStatusActivityType() {
+	case "custom", "playing", "watching", "listening", "competing":
+	default:
+		invalid("status-activity-type", "%q is not one of custom, playing, watching, listening or competing", GetStatusActivityType())
+	}
+	if _, err := logrus.ParseLevel(GetLogLevel()); err != nil {
+		invalid("log-level", "%q is not one of trace, debug, info, warn or error", GetLogLevel())
+	}
+	switch strings.ToLower(GetLogFormat()) {
+	case "json", "text":
+	default:
+		invalid("log-format", "%q is not json or text", GetLogFormat())
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// ApplyTimezone makes the configured timezone the local one
+func ApplyTimezone() error {
+	tz := GetTimezone()
+	if tz == "" {
+		return nil
+	}
+
+	location, err := time.LoadLocation(tz)
+	if err != nil {
+		return err
+	}
+	time.Local = location
+	return nil
+}
+
+// Effective returns the value of every setting in order, with the secrets redacted
+func Effective() []KeyValue {
+	values := make([]KeyValue, 0, len(Settings))
+	for _, setting := range Settings {
+		var value interface{}
+		switch setting.Kind {
+		case List:
+			value = list(setting.Key)
+		case Duration:
+			value = viper.GetDuration(setting.Key).String()
+		case Int:
+			value = viper.GetInt(setting.Key)
+		default:
+			value = viper.GetString(setting.Key)
+		}
+		if setting.Secret && viper.GetString(setting.Key) != "" {
+			value = redacted
+		}
+		if setting.Key == "token" && GetTokenFile() != "" {
+			value = redacted + " (from token-file)"
+		}
+		values = append(values, KeyValue{Key: setting.Key, Value: value})
+	}
+	return values
+}
+
+// KeyValue is a setting with its effective value
+type KeyValue struct {
+	Key   string
+	Value interface{}
+}
+
+// File returns the path of the loaded config file, empty when there is none
+func File() string {
+	if path := viper.ConfigFileUsed(); path != "" {
+		if abs, err := filepath.Abs(path); err == nil {
+			return abs
+		}
+		return path
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// tokenPattern matches the three dot separated parts of a bot token
+var tokenPattern = regexp.MustCompile(`^([A-Za-z0-9_-]{20,})\.([A-Za-z0-9_-]{4,})\.([A-Za-z0-9_-]{20,})$`)
+
+// GetTokenFile returns the path of the file holding the token, as mounted by
+// docker or kubernetes secrets
+func GetTokenFile() string {
+	return viper.GetString("token-file")
+}
+
+// ReadToken returns the bot token from the token setting or the token file,
+// without any "Bot " prefix
+func ReadToken() (string, error) {
+	token := viper.GetString("token")
+	if path := GetTokenFile(); path != "" && token == "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("reading token file: %w", err)
+		}
+		token = string(data)
+	}
+
+	token = strings.TrimSpace(token)
+	return strings.TrimSpace(strings.TrimPrefix(token, "Bot ")), nil
+}
+
+// ValidateToken checks that a token looks like a bot token before connecting
+// with it. The error never contains the token.
+func ValidateToken(token string) error {
+	if token == "" {
+		return errors.New("no token, set token, token-file, DISCORD_TOKEN or DISCORD_TOKEN_FILE")
+	}
+
+	parts := tokenPattern.FindStringSubmatch(token)
+	if parts == nil {
+		return errors.New("the token is not a bot token, expected three parts separated by dots")
+	}
+
+	// the first part is the bot user ID
+	id, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return errors.New("the token is not a bot token, its first part is not base64")
+	}
+	if _, err := strconv.ParseUint(string(id), 10, 64); err != nil {
+		return errors.New("the token is not a bot token, its first part is not a user ID")
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// token builds a token shaped like a real one for the user ID
+func token(id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id)) + ".GhJk1a.abcdefghijklmnopqrstuvwxyz0123456789_-"
+}
+
+func TestValidateToken(t *testing.T) {
+	valid := token("123456789012345678")
+	tests := []struct {
+		name    string
+		token   string
+		wantErr string
+	}{
+		{name: "valid", token: valid},
+		{name: "empty", token: "", wantErr: "no token"},
+		{name: "two parts", token: strings.Join(strings.Split(valid, ".")[:2], "."), wantErr: "three parts"},
+		{name: "four parts", token: valid + ".abcd", wantErr: "three parts"},
+		{name: "short parts", token: "abc.def.ghi", wantErr: "three parts"},
+		{name: "invalid characters", token: strings.Replace(valid, "abc", "a+c", 1), wantErr: "three parts"},
+		{name: "spaces", token: "Bot " + valid, wantErr: "three parts"},
+		{name: "not base64", token: "A" + valid, wantErr: "not base64"},
+		{name: "not a user ID", token: token("not-a-user-id-at-all"), wantErr: "not a user ID"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateToken(test.token)
+			if test.wantErr == "" {
+				if err != nil {
+					t.Fatalf("got %v, want no error", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("got %v, want %q", err, test.wantErr)
+			}
+			if test.token != "" && strings.Contains(err.Error(), test.token) {
+				t.Errorf("the error %q contains the token", err)
+			}
+		})
+	}
+}
+
+func TestReadToken(t *testing.T) {
+	valid := token("123456789012345678")
+	tests := []struct {
+		name  string
+		token string
+		file  string
+		want  string
+	}{
+		{name: "setting", token: valid, want: valid},
+		{name: "bot prefix", token: "Bot " + valid + "\n", want: valid},
+		{name: "file", file: " " + valid + "\n", want: valid},
+		{name: "setting over file", token: valid, file: "other", want: valid},
+		{name: "none"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reset(t)
+			viper.Set("token", test.token)
+			if test.file != "" {
+				viper.Set("token-file", writeFile(t, "token", test.file))
+			}
+
+			got, err := ReadToken()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestEffectiveRedactsToken(t *testing.T) {
+	valid := token("123456789012345678")
+	for _, fromFile := range []bool{false, true} {
+		t.Run(fmt.Sprintf("from file %t", fromFile), func(t *testing.T) {
+			reset(t)
+			if fromFile {
+				viper.Set("token-file", writeFile(t, "token", valid))
+			} else {
+				viper.Set("token", valid)
+			}
+
+			for _, setting := range Effective() {
+				if strings.Contains(fmt.Sprint(setting.Value), valid) {
+					t.Errorf("%s shows the token", setting.Key)
+				}
+			}
+		})
+	}
+}
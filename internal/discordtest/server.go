@@ -0,0 +1,456 @@
+// Package discordtest is a local stand-in for the Discord REST API and
+// gateway, to run the bot end to end without network access or a token.
+package discordtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+)
+
+// AppID is the ID of the bot user and its application
+const AppID = "100000000000000000"
+
+var apiPrefix = "/api/v" + discordgo.APIVersion
+
+// Call is a REST request received by the server
+type Call struct {
+	Method string
+	Path   string
+	Body   []byte
+}
+
+// Status is a presence update sent through the gateway
+type Status struct {
+	Activities []Activity `json:"activities"`
+	AFK        bool       `json:"afk"`
+	Status     string     `json:"status"`
+}
+
+// Activity is an activity of a presence update. discordgo cannot decode the
+// activities it sends, so only the fields set by the bot are kept.
+type Activity struct {
+	Name  string                 `json:"name"`
+	Type  discordgo.ActivityType `json:"type"`
+	State string                 `json:"state"`
+}
+
+// Server serves the REST endpoints used by the bot and a gateway that
+// identifies any token. Everything received is recorded to be inspected.
+type Server struct {
+	*httptest.Server
+
+	upgrader websocket.Upgrader
+
+	mu        sync.Mutex
+	changed   chan struct{}
+	conn      *websocket.Conn
+	connected bool
+	closed    bool
+	sequence  int64
+	nextID    int64
+
+	calls     []Call
+	commands  map[string][]*discordgo.ApplicationCommand
+	responses map[string][]*discordgo.InteractionResponse
+	edits     map[string][]*discordgo.WebhookEdit
+	followups map[string][]*discordgo.WebhookParams
+	statuses  []Status
+}
+
+// NewServer starts a server, the caller must Close it
+func NewServer() *Server {
+	s := &Server{
+		changed:   make(chan struct{}),
+		nextID:    200000000000000000,
+		commands:  map[string][]*discordgo.ApplicationCommand{},
+		responses: map[string][]*discordgo.InteractionResponse{},
+		edits:     map[string][]*discordgo.WebhookEdit{},
+		followups: map[string][]*discordgo.WebhookParams{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /gateway/{$}", s.gateway)
+	mux.HandleFunc("GET "+apiPrefix+"/gateway", s.gatewayURL)
+	mux.HandleFunc("GET "+apiPrefix+"/gateway/bot", s.gatewayURL)
+	mux.HandleFunc("GET "+apiPrefix+"/applications/{app}/commands", s.listCommands)
+	mux.HandleFunc("GET "+apiPrefix+"/applications/{app}/guilds/{guild}/commands", s.listCommands)
+	mux.HandleFunc("POST "+apiPrefix+"/applications/{app}/commands", s.createCommand)
+	mux.HandleFunc("POST "+apiPrefix+"/applications/{app}/guilds/{guild}/commands", s.createCommand)
+	mux.HandleFunc("DELETE "+apiPrefix+"/applications/{app}/commands/{command}", s.deleteCommand)
+	mux.HandleFunc("DELETE "+apiPrefix+"/applications/{app}/guilds/{guild}/commands/{command}", s.deleteCommand)
+	mux.HandleFunc("POST "+apiPrefix+"/interactions/{interaction}/{token}/callback", s.interactionResponse)
+	mux.HandleFunc("PATCH "+apiPrefix+"/webhooks/{app}/{token}/messages/{message}", s.editResponse)
+	mux.HandleFunc("DELETE "+apiPrefix+"/webhooks/{app}/{token}/messages/{message}", s.deleteResponse)
+	mux.HandleFunc("POST "+apiPrefix+"/webhooks/{app}/{token}", s.followup)
+
+	s.Server = httptest.NewServer(s.record(mux))
+	return s
+}
+
+// Session returns a discordgo session whose REST calls and gateway connection
+// go to the server instead of discord
+func (s *Server) Session() *discordgo.Session {
+	dg, _ := discordgo.New("Bot test-token")
+	dg.Client = &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: rewriteTransport{target: s.URL},
+	}
+	dg.ShouldRetryOnRateLimit = false
+	return dg
+}
+
+// Calls returns the REST requests received so far
+func (s *Server) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Call(nil), s.calls...)
+}
+
+// Commands returns the commands registered for a guild, or the global ones for ""
+func (s *Server) Commands(guildID string) []*discordgo.ApplicationCommand {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*discordgo.ApplicationCommand(nil), s.commands[guildID]...)
+}
+
+// Statuses returns the presence updates sent through the gateway
+func (s *Server) Statuses() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Status(nil), s.statuses...)
+}
+
+// Connected reports whether a session identified and is still connected
+func (s *Server) Connected() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connected && !s.closed
+}
+
+// Disconnected reports whether the session closed its gateway connection
+func (s *Server) Disconnected() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// Interact dispatches an INTERACTION_CREATE event to the connected session.
+// The interaction ID and token are filled in when empty.
+func (s *Server) Interact(i *discordgo.Interaction) error {
+	s.mu.Lock()
+	if i.ID == "" {
+		i.ID = s.newID()
+	}
+	if i.Token == "" {
+		i.Token = "token-" + i.ID
+	}
+	if i.AppID == "" {
+		i.AppID = AppID
+	}
+	s.mu.Unlock()
+
+	return s.dispatch("INTERACTION_CREATE", i)
+}
+
+// Reply returns the content the bot answered to an interaction with, either
+// the first response or the last edit of a deferred one
+func (s *Server) Reply(i *discordgo.Interaction) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if edits := s.edits[i.Token]; len(edits) > 0 {
+		if content := edits[len(edits)-1].Content; content != nil {
+			return *content, true
+		}
+	}
+	if followups := s.followups[i.Token]; len(followups) > 0 {
+		return followups[len(followups)-1].Content, true
+	}
+	for _, response := range s.responses[i.ID] {
+		if response.Data != nil && response.Type == discordgo.InteractionResponseChannelMessageWithSource {
+			return response.Data.Content, true
+		}
+	}
+	return "", false
+}
+
+// Responses returns the interaction responses sent for an interaction
+func (s *Server) Responses(i *discordgo.Interaction) []*discordgo.InteractionResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*discordgo.InteractionResponse(nil), s.responses[i.ID]...)
+}
+
+// Wait blocks until condition holds or the timeout expires, reporting which
+func (s *Server) Wait(timeout time.Duration, condition func() bool) bool {
+	deadline := time.After(timeout)
+	for {
+		s.mu.Lock()
+		changed := s.changed
+		s.mu.Unlock()
+
+		if condition() {
+			return true
+		}
+
+		select {
+		case <-changed:
+		case <-deadline:
+			return condition()
+		}
+	}
+}
+
+// notify wakes up the callers of Wait, callers must hold mu
+func (s *Server) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// newID returns a new snowflake, callers must hold mu
+func (s *Server) newID() string {
+	s.nextID++
+	return strconv.FormatInt(s.nextID, 10)
+}
+
+func (s *Server) record(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(strings.NewReader(string(body)))
+
+		if r.URL.Path != "/gateway/" {
+			s.mu.Lock()
+			s.calls = append(s.calls, Call{Method: r.Method, Path: r.URL.Path, Body: body})
+			s.mu.Unlock()
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) gatewayURL(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]interface{}{
+		"url":    "ws" + strings.TrimPrefix(s.URL, "http") + "/gateway/",
+		"shards": 1,
+	})
+}
+
+func (s *Server) listCommands(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, s.Commands(r.PathValue("guild")))
+}
+
+func (s *Server) createCommand(w http.ResponseWriter, r *http.Request) {
+	var command discordgo.ApplicationCommand
+	if !readJSON(w, r, &command) {
+		return
+	}
+
+	guildID := r.PathValue("guild")
+	s.mu.Lock()
+	command.ID = s.newID()
+	command.ApplicationID = r.PathValue("app")
+	command.GuildID = guildID
+	s.commands[guildID] = append(s.commands[guildID], &command)
+	s.notify()
+	s.mu.Unlock()
+
+	writeJSON(w, command)
+}
+
+func (s *Server) deleteCommand(w http.ResponseWriter, r *http.Request) {
+	guildID := r.PathValue("guild")
+	s.mu.Lock()
+	commands := s.commands[guildID][:0]
+	for _, command := range s.commands[guildID] {
+		if command.ID != r.PathValue("command") {
+			commands = append(commands, command)
+		}
+	}
+	s.commands[guildID] = commands
+	s.notify()
+	s.mu.Unlock()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) interactionResponse(w http.ResponseWriter, r *http.Request) {
+	var response discordgo.InteractionResponse
+	if !readJSON(w, r, &response) {
+		return
+	}
+
+	s.mu.Lock()
+	s.responses[r.PathValue("interaction")] = append(s.responses[r.PathValue("interaction")], &response)
+	s.notify()
+	s.mu.Unlock()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) editResponse(w http.ResponseWriter, r *http.Request) {
+	var edit discordgo.WebhookEdit
+	if !readJSON(w, r, &edit) {
+		return
+	}
+
+	s.mu.Lock()
+	s.edits[r.PathValue("token")] = append(s.edits[r.PathValue("token")], &edit)
+	id := s.newID()
+	s.notify()
+	s.mu.Unlock()
+
+	message := &discordgo.Message{ID: id}
+	if edit.Content != nil {
+		message.Content = *edit.Content
+	}
+	writeJSON(w, message)
+}
+
+func (s *Server) deleteResponse(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) followup(w http.ResponseWriter, r *http.Request) {
+	var params discordgo.WebhookParams
+	if !readJSON(w, r, &params) {
+		return
+	}
+
+	s.mu.Lock()
+	s.followups[r.PathValue("token")] = append(s.followups[r.PathValue("token")], &params)
+	id := s.newID()
+	s.notify()
+	s.mu.Unlock()
+
+	writeJSON(w, &discordgo.Message{ID: id, Content: params.Content})
+}
+
+// gateway speaks the gateway protocol: hello, identify, ready, heartbeats and presence updates
+func (s *Server) gateway(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logrus.WithError(err).Error("Fake gateway failed to upgrade connection")
+		return
+	}
+	defer conn.Close()
+
+	s.mu.Lock()
+	s.conn = conn
+	s.closed = false
+	s.mu.Unlock()
+
+	if err := s.send(0, "", map[string]interface{}{"heartbeat_interval": 45000}, 10); err != nil {
+		return
+	}
+
+	for {
+		var payload struct {
+			Op   int             `json:"op"`
+			Data json.RawMessage `json:"d"`
+		}
+		if err := conn.ReadJSON(&payload); err != nil {
+			s.mu.Lock()
+			s.closed = true
+			s.connected = false
+			s.notify()
+			s.mu.Unlock()
+			return
+		}
+
+		switch payload.Op {
+		case 1:
+			s.send(0, "", nil, 11)
+		case 2:
+			s.mu.Lock()
+			s.connected = true
+			s.notify()
+			s.mu.Unlock()
+			s.dispatch("READY", map[string]interface{}{
+				"v":          9,
+				"session_id": "session",
+				"user": map[string]interface{}{
+					"id":       AppID,
+					"username": "alum-bot",
+					"bot":      true,
+				},
+				"application": map[string]interface{}{"id": AppID},
+				"guilds":      []interface{}{},
+			})
+		case 3:
+			var status Status
+			if err := json.Unmarshal(payload.Data, &status); err == nil {
+				s.mu.Lock()
+				s.statuses = append(s.statuses, status)
+				s.notify()
+				s.mu.Unlock()
+			}
+		}
+	}
+}
+
+// dispatch sends an event to the connected session
+func (s *Server) dispatch(event string, data interface{}) error {
+	s.mu.Lock()
+	s.sequence++
+	sequence := s.sequence
+	s.mu.Unlock()
+
+	return s.send(sequence, event, data, 0)
+}
+
+func (s *Server) send(sequence int64, event string, data interface{}, op int) error {
+	payload := map[string]interface{}{"op": op, "d": data}
+	if op == 0 {
+		payload["s"] = sequence
+		payload["t"] = event
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return fmt.Errorf("no session connected to the gateway")
+	}
+	return s.conn.WriteJSON(payload)
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// rewriteTransport sends every request to the target server, keeping the path
+type rewriteTransport struct {
+	target string
+}
+
+func (t rewriteTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	target, err := url.Parse(t.target)
+	if err != nil {
+		return nil, err
+	}
+
+	r = r.Clone(r.Context())
+	r.URL.Scheme = target.Scheme
+	r.URL.Host = target.Host
+	r.Host = target.Host
+	return http.DefaultTransport.RoundTrip(r)
+}
@@ -0,0 +1,18 @@
+// Package filelock serializes the instances of the bot sharing a data
+// directory, so a read, change and write of a shared file is not interleaved
+// with another one.
+package filelock
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Lock blocks until it holds the exclusive lock on path, creating the file,
+// and returns the function releasing it
+func Lock(path string) (unlock func(), err error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	return lock(path)
+}
@@ -0,0 +1,41 @@
+//go:build !unix
+
+package filelock
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	retryEvery = 10 * time.Millisecond
+	// staleAfter is how old a lock file is taken as left by a dead process
+	staleAfter = 30 * time.Second
+)
+
+// lock creates path exclusively, waiting while another instance holds it
+func lock(path string) (func(), error) {
+	for {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			file.Close()
+			return func() {
+				if err := os.Remove(path); err != nil {
+					logrus.WithError(err).WithField("lock", path).Warn("Failed to release file lock")
+				}
+			}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleAfter {
+			os.Remove(path)
+			continue
+		}
+		time.Sleep(retryEvery)
+	}
+}
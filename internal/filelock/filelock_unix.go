@@ -0,0 +1,35 @@
+//go:build unix
+
+package filelock
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+)
+
+// lock takes a flock on path, released by the kernel if the process dies
+func lock(path string) (func(), error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX)
+		if err != syscall.EINTR {
+			break
+		}
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return func() {
+		if err := syscall.Flock(int(file.Fd()), syscall.LOCK_UN); err != nil {
+			logrus.WithError(err).WithField("lock", path).Warn("Failed to release file lock")
+		}
+		file.Close()
+	}, nil
+}
@@ -0,0 +1,149 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/FGasquez/alum-bot/internal/cache"
+	"github.com/FGasquez/alum-bot/internal/clock"
+	"github.com/FGasquez/alum-bot/internal/config"
+	"github.com/FGasquez/alum-bot/internal/messages"
+	"github.com/FGasquez/alum-bot/internal/metrics"
+	"github.com/FGasquez/alum-bot/internal/sources"
+)
+
+// disconnectGrace is how long the gateway may be down before the bot is unhealthy,
+// discordgo reconnects by itself so short outages are expected
+const disconnectGrace = 5 * time.Minute
+
+// holidaysTimeout bounds the holidays lookup of the readiness check
+const holidaysTimeout = 5 * time.Second
+
+var (
+	mu        sync.RWMutex
+	connected bool
+	// since is when the gateway state last changed, or the start of the process
+	since = time.Now()
+)
+
+// SetConnected records the state of the Discord gateway connection
+func SetConnected(up bool) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if up != connected {
+		since = time.Now()
+	}
+	connected = up
+	if up {
+		metrics.GatewayConnected.Set(1)
+	} else {
+		metrics.GatewayConnected.Set(0)
+	}
+}
+
+func gateway() (bool, time.Time) {
+	mu.RLock()
+	defer mu.RUnlock()
+	return connected, since
+}
+
+// Report is the body of the health endpoints
+type Report struct {
+	Status   string   `json:"status"`
+	Discord  Discord  `json:"discord"`
+	Holidays Holidays `json:"holidays"`
+	Messages Messages `json:"messages"`
+}
+
+type Discord struct {
+	Connected bool      `json:"connected"`
+	Since     time.Time `json:"since"`
+}
+
+type Holidays struct {
+	LastFetch *time.Time `json:"lastFetch,omitempty"`
+	// CacheAges is the age of each cached year, by country and year
+	CacheAges map[string]string `json:"cacheAges"`
+	Error     string            `json:"error,omitempty"`
+}
+
+type Messages struct {
+	File  string `json:"file,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Check builds the report of the bot state. The holidays of the default
+// country are only looked up when ready is set.
+func Check(ctx context.Context, ready bool) (Report, bool) {
+	up, changed := gateway()
+	report := Report{
+		Discord:  Discord{Connected: up, Since: changed},
+		Holidays: Holidays{CacheAges: map[string]string{}},
+		Messages: Messages{File: config.GetMessagesPath()},
+	}
+
+	if lastFetch := cache.Default().LastFetch(); !lastFetch.IsZero() {
+		report.Holidays.LastFetch = &lastFetch
+	}
+	for key, age := range cache.Default().Ages() {
+		report.Holidays.CacheAges[key] = age.Round(time.Second).String()
+	}
+
+	ok := up || time.Since(changed) < disconnectGrace
+	if err := messages.CheckFile(); err != nil {
+		report.Messages.Error = err.Error()
+		ok = false
+	}
+
+	if ready {
+		ok = ok && up
+		if err := checkHolidays(ctx); err != nil {
+			report.Holidays.Error = err.Error()
+			ok = false
+		}
+	}
+
+	report.Status = "ok"
+	if !ok {
+		report.Status = "unavailable"
+	}
+	return report, ok
+}
+
+func checkHolidays(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, holidaysTimeout)
+	defer cancel()
+
+	provider, err := sources.Get(config.GetDefaultCountry())
+	if err != nil {
+		return err
+	}
+	_, err = provider.Holidays(ctx, clock.Now().Year())
+	return err
+}
+
+// Healthz answers whether the bot is alive: the gateway is up or
+// reconnecting, and the messages file loads
+func Healthz(w http.ResponseWriter, r *http.Request) {
+	report, ok := Check(r.Context(), false)
+	write(w, report, ok)
+}
+
+// Readyz answers whether the bot can serve commands: the gateway is up, the
+// messages file loads and the holidays of the default country are available
+func Readyz(w http.ResponseWriter, r *http.Request) {
+	report, ok := Check(r.Context(), true)
+	write(w, report, ok)
+}
+
+func write(w http.ResponseWriter, report Report, ok bool) {
+	w.Header().Set("Content-Type", "application/json")
+	if !ok {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,32 @@
+package helpers
+
+import (
+	"fmt"
+	"time"
+)
+
+// DateForYear resolves a holiday date for the given year. Full dates
+// (yyyy-mm-dd) only match their own year, while month and day dates (mm-dd)
+// repeat every year. It returns false when the date does not apply to the year
+// or is not valid.
+func DateForYear(date string, year int) (string, bool) {
+	if _, err := time.Parse("01-02", date); err == nil {
+		date = fmt.Sprintf("%d-%s", year, date)
+	}
+
+	parsedDate, err := time.Parse("2006-01-02", date)
+	if err != nil || parsedDate.Year() != year {
+		return "", false
+	}
+
+	return date, true
+}
+
+// ValidHolidayDate reports whether date is a full date (yyyy-mm-dd) or a month and day (mm-dd)
+func ValidHolidayDate(date string) bool {
+	if _, err := time.Parse("01-02", date); err == nil {
+		return true
+	}
+	_, err := time.Parse("2006-01-02", date)
+	return err == nil
+}
@@ -82,3 +82,45 @@ func FormatDate(dateStr string) string {
 
 	return fmt.Sprintf("%s %d de %s", weekday, day, month)
 }
+
+// Weekday returns the Spanish name of the weekday of a yyyy-mm-dd date, empty when it is not valid
+func Weekday(dateStr string) string {
+	t, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		return ""
+	}
+	return spanishWeekdays[t.Weekday()]
+}
+
+// RelativeDays describes in Spanish a day that is days away from today, e.g.
+// "mañana", "en 5 días" or "en 2 semanas"
+func RelativeDays(days int) string {
+	switch {
+	case days == 0:
+		return "hoy"
+	case days == 1:
+		return "mañana"
+	case days == 2:
+		return "pasado mañana"
+	case days == -1:
+		return "ayer"
+	case days == -2:
+		return "anteayer"
+	case days < 0:
+		return "hace " + relativeAmount(-days)
+	default:
+		return "en " + relativeAmount(days)
+	}
+}
+
+// relativeAmount rounds days to weeks from two weeks on, and to months from two months on
+func relativeAmount(days int) string {
+	switch {
+	case days >= 60:
+		return fmt.Sprintf("%d meses", (days+15)/30)
+	case days >= 14:
+		return fmt.Sprintf("%d semanas", (days+3)/7)
+	default:
+		return fmt.Sprintf("%d días", days)
+	}
+}
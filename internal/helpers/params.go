@@ -2,17 +2,46 @@ package helpers
 
 import "github.com/bwmarrin/discordgo"
 
+// IntParam converts an integer option value, which discord sends as a JSON number, to int
+func IntParam(value interface{}) int {
+	switch v := value.(type) {
+	case float64:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
+
 func GetParams(options []*discordgo.ApplicationCommandInteractionDataOption) map[string]interface{} {
 	params := make(map[string]interface{})
 	for _, option := range options {
 		switch option.Type {
-		case discordgo.ApplicationCommandOptionSubCommand:
-			GetParams(option.Options)
-		case discordgo.ApplicationCommandOptionSubCommandGroup:
-			GetParams(option.Options)
+		case discordgo.ApplicationCommandOptionSubCommand, discordgo.ApplicationCommandOptionSubCommandGroup:
+			for name, value := range GetParams(option.Options) {
+				params[name] = value
+			}
 		default:
 			params[option.Name] = option.Value
 		}
 	}
 	return params
 }
+
+// UserID returns the ID of the user that triggered the interaction, both in guilds and DMs
+func UserID(i *discordgo.InteractionCreate) string {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User.ID
+	}
+	if i.User != nil {
+		return i.User.ID
+	}
+	return ""
+}
+
+// CanManageGuild reports whether the user that triggered the interaction can manage the guild
+func CanManageGuild(i *discordgo.InteractionCreate) bool {
+	return i.Member != nil && i.Member.Permissions&discordgo.PermissionManageGuild != 0
+}
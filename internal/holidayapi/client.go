@@ -0,0 +1,170 @@
+package holidayapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/FGasquez/alum-bot/internal/config"
+	"github.com/FGasquez/alum-bot/internal/logging"
+	"github.com/FGasquez/alum-bot/internal/metrics"
+	"github.com/FGasquez/alum-bot/internal/types"
+)
+
+const (
+	DefaultBaseURL = "https://api.argentinadatos.com/v1/feriados"
+
+	// responses bigger than this are not a list of holidays
+	maxResponseSize = 1 << 20
+)
+
+// Client fetches holidays from the argentinadatos API
+type Client struct {
+	HTTPClient *http.Client
+	BaseURL    string
+	// Retries is the number of extra attempts after a timeout or a server error
+	Retries int
+	// Backoff is the wait before the first retry, doubled on every retry
+	Backoff time.Duration
+}
+
+// NewClient returns a client configured from the api-* settings
+func NewClient() *Client {
+	return &Client{
+		HTTPClient: &http.Client{Timeout: config.GetAPITimeout()},
+		BaseURL:    DefaultBaseURL,
+		Retries:    config.GetAPIRetries(),
+		Backoff:    config.GetAPIBackoff(),
+	}
+}
+
+// Fetch returns the raw, already validated, response with the holidays of the given year
+func (c *Client) Fetch(ctx context.Context, year int) ([]byte, error) {
+	var err error
+	backoff := c.Backoff
+
+	for attempt := 0; attempt <= c.Retries; attempt++ {
+		if attempt > 0 {
+			logging.FromContext(ctx).WithError(err).WithField("attempt", attempt).Warn("Retrying holiday api request")
+			select {
+			case <-ctx.Done():
+				return nil, &Error{Kind: ErrTimeout, Err: ctx.Err()}
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		var data []byte
+		data, err = c.fetch(ctx, year)
+		if err == nil {
+			return data, nil
+		}
+		if !retryable(err) || ctx.Err() != nil {
+			break
+		}
+	}
+
+	return nil, err
+}
+
+// Holidays returns the holidays of the given year
+func (c *Client) Holidays(ctx context.Context, year int) ([]types.Holiday, error) {
+	data, err := c.Fetch(ctx, year)
+	if err != nil {
+		return nil, err
+	}
+
+	return Decode(data, year)
+}
+
+func (c *Client) fetch(ctx context.Context, year int) ([]byte, error) {
+	url := fmt.Sprintf("%s/%d", c.BaseURL, year)
+	logging.FromContext(ctx).WithField("url", url).Info("Getting holidays")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, &Error{Kind: ErrUnavailable, Err: err}
+	}
+	req.Header.Set("Accept", "application/json")
+
+	start := time.Now()
+	resp, err := c.HTTPClient.Do(req)
+	metrics.HolidayAPIDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		kind := classify(err)
+		status := "error"
+		if errors.Is(kind, ErrTimeout) {
+			status = "timeout"
+		}
+		metrics.HolidayAPIRequests.WithLabelValues(status).Inc()
+		return nil, &Error{Kind: kind, Err: err}
+	}
+	defer resp.Body.Close()
+	metrics.HolidayAPIRequests.WithLabelValues(strconv.Itoa(resp.StatusCode)).Inc()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, &Error{Kind: ErrNotFound, StatusCode: resp.StatusCode}
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return nil, &Error{Kind: ErrUnavailable, StatusCode: resp.StatusCode}
+	case resp.StatusCode != http.StatusOK:
+		return nil, &Error{Kind: ErrInvalidResponse, StatusCode: resp.StatusCode}
+	}
+
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		return nil, &Error{Kind: ErrInvalidResponse, StatusCode: resp.StatusCode, Err: fmt.Errorf("unexpected content type %q", resp.Header.Get("Content-Type"))}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, &Error{Kind: classify(err), Err: err}
+	}
+
+	if _, err := Decode(data, year); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// Decode parses a list of holidays and checks that every entry has a valid date of the given year and a name
+func Decode(data []byte, year int) ([]types.Holiday, error) {
+	var holidays []types.Holiday
+	if err := json.Unmarshal(data, &holidays); err != nil {
+		return nil, &Error{Kind: ErrInvalidResponse, Err: err}
+	}
+	if holidays == nil {
+		return nil, &Error{Kind: ErrInvalidResponse, Err: errors.New("response is not a list")}
+	}
+
+	for i, h := range holidays {
+		date, err := time.Parse("2006-01-02", h.Date)
+		if err != nil {
+			return nil, &Error{Kind: ErrInvalidResponse, Err: fmt.Errorf("entry %d: invalid date %q", i, h.Date)}
+		}
+		if date.Year() != year {
+			return nil, &Error{Kind: ErrInvalidResponse, Err: fmt.Errorf("entry %d: date %s is not in %d", i, h.Date, year)}
+		}
+		if h.Name == "" {
+			return nil, &Error{Kind: ErrInvalidResponse, Err: fmt.Errorf("entry %d: missing name", i)}
+		}
+	}
+
+	return holidays, nil
+}
+
+func classify(err error) error {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrTimeout
+	}
+	return ErrUnavailable
+}
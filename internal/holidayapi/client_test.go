@@ -0,0 +1,179 @@
+package holidayapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const holidays2025 = `[{"fecha": "2025-07-09", "tipo": "inamovible", "nombre": "Día de la Independencia"}]`
+
+// reply answers one request
+type reply func(w http.ResponseWriter)
+
+func jsonReply(status int, body string) reply {
+	return func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}
+}
+
+func slowReply(w http.ResponseWriter) {
+	time.Sleep(100 * time.Millisecond)
+	jsonReply(http.StatusOK, holidays2025)(w)
+}
+
+// serve answers the requests with replies in order, repeating the last one,
+// and returns the client and the count of requests
+func serve(t *testing.T, replies ...reply) (*Client, *int32) {
+	t.Helper()
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&calls, 1)) - 1
+		if r.URL.Path != "/2025" {
+			t.Errorf("requested %s, want /2025", r.URL.Path)
+		}
+		replies[min(n, len(replies)-1)](w)
+	}))
+	t.Cleanup(server.Close)
+
+	return &Client{
+		HTTPClient: &http.Client{Timeout: 50 * time.Millisecond},
+		BaseURL:    server.URL,
+		Retries:    2,
+		Backoff:    time.Millisecond,
+	}, &calls
+}
+
+func TestHolidays(t *testing.T) {
+	tests := []struct {
+		name    string
+		replies []reply
+		want    error
+		status  int
+		calls   int32
+	}{
+		{name: "ok", replies: []reply{jsonReply(200, holidays2025)}, calls: 1},
+		{name: "server error then ok", replies: []reply{jsonReply(500, ""), jsonReply(200, holidays2025)}, calls: 2},
+		{name: "rate limited then ok", replies: []reply{jsonReply(429, ""), jsonReply(200, holidays2025)}, calls: 2},
+		{name: "timeout then ok", replies: []reply{slowReply, jsonReply(200, holidays2025)}, calls: 2},
+		{name: "server errors", replies: []reply{jsonReply(503, "")}, want: ErrUnavailable, status: 503, calls: 3},
+		{name: "timeouts", replies: []reply{slowReply}, want: ErrTimeout, calls: 3},
+		{name: "not found", replies: []reply{jsonReply(404, "")}, want: ErrNotFound, status: 404, calls: 1},
+		{name: "bad request", replies: []reply{jsonReply(400, "")}, want: ErrInvalidResponse, status: 400, calls: 1},
+		{name: "wrong content type", replies: []reply{func(w http.ResponseWriter) {
+			w.Header().Set("Content-Type", "text/html")
+			io.WriteString(w, "<html></html>")
+		}}, want: ErrInvalidResponse, status: 200, calls: 1},
+		{name: "malformed body", replies: []reply{jsonReply(200, `[{"fecha": `)}, want: ErrInvalidResponse, calls: 1},
+		{name: "not a list", replies: []reply{jsonReply(200, `null`)}, want: ErrInvalidResponse, calls: 1},
+		{name: "invalid entry", replies: []reply{jsonReply(200, `[{"fecha": "2025-07-09"}]`)}, want: ErrInvalidResponse, calls: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, calls := serve(t, test.replies...)
+			holidays, err := client.Holidays(context.Background(), 2025)
+
+			if test.want == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(holidays) != 1 || holidays[0].Name != "Día de la Independencia" {
+					t.Errorf("got %+v", holidays)
+				}
+			} else {
+				if !errors.Is(err, test.want) {
+					t.Fatalf("got %v, want %v", err, test.want)
+				}
+				var apiErr *Error
+				if !errors.As(err, &apiErr) || apiErr.StatusCode != test.status {
+					t.Errorf("got %#v, want an *Error with status %d", err, test.status)
+				}
+			}
+			if got := atomic.LoadInt32(calls); got != test.calls {
+				t.Errorf("%d requests, want %d", got, test.calls)
+			}
+		})
+	}
+}
+
+func TestFetchStopsRetryingWhenCancelled(t *testing.T) {
+	client, calls := serve(t, jsonReply(503, ""))
+	client.Backoff = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := client.Fetch(ctx, 2025)
+	if !errors.Is(err, ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want a timeout wrapping the deadline", err)
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("%d requests, want 1", got)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		valid bool
+	}{
+		{name: "valid", data: holidays2025, valid: true},
+		{name: "empty list", data: `[]`, valid: true},
+		{name: "not json", data: `feriados`},
+		{name: "object", data: `{"fecha": "2025-07-09"}`},
+		{name: "null", data: `null`},
+		{name: "invalid date", data: `[{"fecha": "09/07/2025", "nombre": "Independencia"}]`},
+		{name: "other year", data: `[{"fecha": "2024-07-09", "nombre": "Independencia"}]`},
+		{name: "missing name", data: `[{"fecha": "2025-07-09"}]`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Decode([]byte(test.data), 2025)
+			if test.valid && err != nil {
+				t.Errorf("got %v, want no error", err)
+			}
+			if !test.valid && !errors.Is(err, ErrInvalidResponse) {
+				t.Errorf("got %v, want %v", err, ErrInvalidResponse)
+			}
+		})
+	}
+}
+
+func TestError(t *testing.T) {
+	cause := errors.New("connection reset")
+	tests := []struct {
+		name string
+		err  *Error
+		want string
+		is   []error
+	}{
+		{name: "kind", err: &Error{Kind: ErrNotFound}, want: "holidays not found", is: []error{ErrNotFound}},
+		{name: "status", err: &Error{Kind: ErrUnavailable, StatusCode: 502}, want: "holiday api unavailable (status 502)", is: []error{ErrUnavailable}},
+		{name: "cause", err: &Error{Kind: ErrUnavailable, Err: cause}, want: "holiday api unavailable: connection reset", is: []error{ErrUnavailable, cause}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.err.Error(); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+			for _, target := range test.is {
+				if !errors.Is(test.err, target) {
+					t.Errorf("%v is not %v", test.err, target)
+				}
+			}
+			if errors.Is(test.err, ErrTimeout) {
+				t.Errorf("%v is a timeout", test.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package holidayapi
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of errors returned by the client, check them with errors.Is
+var (
+	// ErrTimeout means the API did not answer in time
+	ErrTimeout = errors.New("holiday api timed out")
+	// ErrUnavailable means the API could not be reached or answered with a server error
+	ErrUnavailable = errors.New("holiday api unavailable")
+	// ErrNotFound means the API has no holidays for the year asked for
+	ErrNotFound = errors.New("holidays not found")
+	// ErrInvalidResponse means the API answered with something that is not a list of holidays
+	ErrInvalidResponse = errors.New("invalid holiday api response")
+)
+
+// Error describes a failed request to the holiday API
+type Error struct {
+	Kind       error
+	StatusCode int
+	Err        error
+}
+
+func (e *Error) Error() string {
+	msg := e.Kind.Error()
+	if e.StatusCode != 0 {
+		msg = fmt.Sprintf("%s (status %d)", msg, e.StatusCode)
+	}
+	if e.Err != nil {
+		msg = fmt.Sprintf("%s: %v", msg, e.Err)
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// retryable reports whether a new attempt may succeed
+func retryable(err error) bool {
+	return errors.Is(err, ErrTimeout) || errors.Is(err, ErrUnavailable)
+}
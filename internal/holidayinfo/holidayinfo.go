@@ -0,0 +1,139 @@
+package holidayinfo
+
+import (
+	_ "embed"
+	"strings"
+	"unicode"
+
+	"github.com/FGasquez/alum-bot/internal/types"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+	"gopkg.in/yaml.v2"
+)
+
+//go:embed ar.yaml
+var argentina []byte
+
+// Info describes a national holiday. A holiday matches it when its name
+// contains one of Names, or, for the holidays that never move, when it falls
+// on one of Dates (mm-dd).
+type Info struct {
+	Name        string   `yaml:"name"`
+	Names       []string `yaml:"names"`
+	Dates       []string `yaml:"dates"`
+	Description string   `yaml:"description"`
+	History     string   `yaml:"history"`
+	Link        string   `yaml:"link"`
+}
+
+// infos are the Argentine holidays, the only ones described so far
+var infos = load(argentina)
+
+// byCountry are the holidays described for each country
+var byCountry = map[string][]Info{"AR": infos}
+
+func load(data []byte) []Info {
+	var infos []Info
+	if err := yaml.Unmarshal(data, &infos); err != nil {
+		logrus.WithError(err).Error("Failed to load the holiday information")
+	}
+	return infos
+}
+
+// All returns the information of every holiday, in calendar order
+func All() []Info {
+	return infos
+}
+
+// Lookup returns the information of the holiday called name, matched
+// ignoring case and accents against its name or any of its names
+func Lookup(name string) (Info, bool) {
+	name = normalize(name)
+	if name == "" {
+		return Info{}, false
+	}
+	for _, info := range infos {
+		if normalize(info.Name) == name {
+			return info, true
+		}
+	}
+	return byName(infos, name)
+}
+
+// Search returns the information of the holidays whose name contains text,
+// ignoring case and accents, every one when text is empty
+func Search(text string) []Info {
+	text = normalize(text)
+	var found []Info
+	for _, info := range infos {
+		if strings.Contains(normalize(info.Name), text) {
+			found = append(found, info)
+			continue
+		}
+		for _, name := range info.Names {
+			if strings.Contains(normalize(name), text) {
+				found = append(found, info)
+				break
+			}
+		}
+	}
+	return found
+}
+
+// For returns the information of a holiday of country, by its name and then
+// by its date. Custom, regional, bridge and weekend days are only matched by
+// name. Countries without information never match, even on the dates and
+// names they share with Argentina, such as Navidad.
+func For(holiday types.ParsedHolidays, country string) (Info, bool) {
+	infos, ok := byCountry[strings.ToUpper(strings.TrimSpace(country))]
+	if !ok {
+		return Info{}, false
+	}
+	if info, ok := byName(infos, normalize(holiday.Name)); ok {
+		return info, true
+	}
+
+	switch holiday.Type {
+	case types.Custom, types.Provincial, types.Bridge, types.Weekend:
+		return Info{}, false
+	}
+	if len(holiday.Date) != len("2006-01-02") {
+		return Info{}, false
+	}
+	day := holiday.Date[5:]
+	for _, info := range infos {
+		for _, date := range info.Dates {
+			if date == day {
+				return info, true
+			}
+		}
+	}
+	return Info{}, false
+}
+
+// byName returns the first of infos with a name contained in name
+func byName(infos []Info, name string) (Info, bool) {
+	if name == "" {
+		return Info{}, false
+	}
+	for _, info := range infos {
+		for _, pattern := range info.Names {
+			if strings.Contains(name, normalize(pattern)) {
+				return info, true
+			}
+		}
+	}
+	return Info{}, false
+}
+
+// normalize lowercases s and removes its accents, so "Güemes" matches "guemes"
+func normalize(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	result, _, err := transform.String(t, s)
+	if err != nil {
+		result = s
+	}
+	return strings.ToLower(strings.TrimSpace(result))
+}
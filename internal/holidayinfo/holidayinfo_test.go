@@ -0,0 +1,44 @@
+package holidayinfo
+
+import (
+	"testing"
+
+	"github.com/FGasquez/alum-bot/internal/types"
+)
+
+func TestFor(t *testing.T) {
+	tests := []struct {
+		name    string
+		country string
+		holiday types.ParsedHolidays
+		want    string
+	}{
+		{name: "by name", country: "AR", holiday: types.ParsedHolidays{Date: "2025-07-09", Name: "Día de la Independencia"}, want: "Día de la Independencia"},
+		{name: "by date", country: "AR", holiday: types.ParsedHolidays{Date: "2025-12-25", Name: "Christmas Day"}, want: "Navidad"},
+		{name: "lowercase country", country: "ar", holiday: types.ParsedHolidays{Date: "2025-05-01", Name: "Día del Trabajador"}, want: "Día del Trabajador"},
+		{name: "bridge only by name", country: "AR", holiday: types.ParsedHolidays{Date: "2025-05-25", Type: types.Bridge, Name: "Puente turístico"}},
+		{name: "chilean independence", country: "CL", holiday: types.ParsedHolidays{Date: "2025-09-18", Name: "Independencia Nacional"}},
+		{name: "uruguayan independence", country: "UY", holiday: types.ParsedHolidays{Date: "2025-08-25", Name: "Declaratoria de la Independencia"}},
+		{name: "chilean new year", country: "CL", holiday: types.ParsedHolidays{Date: "2025-01-01", Name: "Año Nuevo"}},
+		{name: "uruguayan new year", country: "UY", holiday: types.ParsedHolidays{Date: "2025-01-01", Name: "Año Nuevo"}},
+		{name: "chilean labour day", country: "CL", holiday: types.ParsedHolidays{Date: "2025-05-01", Name: "Día Nacional del Trabajo"}},
+		{name: "uruguayan labour day", country: "UY", holiday: types.ParsedHolidays{Date: "2025-05-01", Name: "Día de los Trabajadores"}},
+		{name: "chilean christmas", country: "CL", holiday: types.ParsedHolidays{Date: "2025-12-25", Name: "Navidad"}},
+		{name: "uruguayan christmas", country: "UY", holiday: types.ParsedHolidays{Date: "2025-12-25", Name: "Día de la Familia"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			info, ok := For(test.holiday, test.country)
+			if test.want == "" {
+				if ok {
+					t.Errorf("got %q, want no information", info.Name)
+				}
+				return
+			}
+			if !ok || info.Name != test.want {
+				t.Errorf("got %q, %v, want %q", info.Name, ok, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+// Package jsonstore keeps the data of a JSON file in the data directory,
+// which may be shared by several instances of the bot. The file is read again
+// whenever another instance replaced it, and changed holding a file lock, so
+// the instances keep each other's changes.
+package jsonstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/FGasquez/alum-bot/internal/filelock"
+	"github.com/sirupsen/logrus"
+)
+
+// Store holds the data of a JSON file of type T
+type Store[T any] struct {
+	path func() string
+	// init fills the empty fields of the data, e.g. its maps
+	init func(*T)
+
+	mu     sync.Mutex
+	loaded *T
+	stamp  stamp
+	// err is why the file could not be read, nothing is saved over it until it is fixed
+	err      error
+	revision uint64
+}
+
+// New returns the store of the file at path, which is called on every access
+// so it follows the configured data directory
+func New[T any](path func() string, init func(*T)) *Store[T] {
+	return &Store[T]{path: path, init: init}
+}
+
+// stamp tells apart the versions of a file. Every save replaces the file, so
+// its identity changes even when its time and size do not.
+type stamp struct {
+	info os.FileInfo
+}
+
+func stampOf(path string) stamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return stamp{}
+	}
+	return stamp{info: info}
+}
+
+func (s stamp) same(other stamp) bool {
+	if s.info == nil || other.info == nil {
+		return s.info == nil && other.info == nil
+	}
+	return os.SameFile(s.info, other.info) && s.info.ModTime().Equal(other.info.ModTime()) && s.info.Size() == other.info.Size()
+}
+
+// Read calls read with the data of the file, read again when it changed.
+// The data is empty when the file is missing or broken.
+func (s *Store[T]) Read(read func(data *T)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	read(s.load())
+}
+
+// Update calls change with the data of the file, holding the file lock, and
+// saves it when change reports a change. It fails without saving when the
+// file could not be read, so a broken file is never overwritten.
+func (s *Store[T]) Update(change func(data *T) bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := s.path()
+	unlock, err := filelock.Lock(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	data := s.load()
+	if s.err != nil {
+		return s.err
+	}
+	if !change(data) {
+		return nil
+	}
+	s.revision++
+	return s.save(path, data)
+}
+
+// Revision returns a number that changes whenever the data is read or updated
+func (s *Store[T]) Revision() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.load()
+	return s.revision
+}
+
+// Reset forgets the data read, so it is read again, e.g. from another data directory
+func (s *Store[T]) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loaded = nil
+}
+
+// load returns the data of the file, reading it again when it changed on
+// disk, callers must hold mu
+func (s *Store[T]) load() *T {
+	path := s.path()
+	current := stampOf(path)
+	if s.loaded != nil && current.same(s.stamp) {
+		return s.loaded
+	}
+
+	s.revision++
+	s.stamp = current
+	s.loaded = new(T)
+	s.err = s.read(path, s.loaded)
+	if s.err != nil {
+		logrus.WithError(s.err).Error("Failed to read data file, changes are refused until it is fixed or moved aside")
+		s.loaded = new(T)
+	}
+	s.init(s.loaded)
+	return s.loaded
+}
+
+func (s *Store[T]) read(path string, data *T) error {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(content, data); err != nil {
+		return fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return nil
+}
+
+// save writes the file atomically, callers must hold mu and the file lock
+func (s *Store[T]) save(path string, data *T) error {
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	s.stamp = stampOf(path)
+	return nil
+}
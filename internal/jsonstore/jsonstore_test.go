@@ -0,0 +1,91 @@
+package jsonstore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type counts struct {
+	Counts map[string]int `json:"counts"`
+}
+
+func newStore(t *testing.T) (*Store[counts], string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "counts.json")
+	return New(func() string { return path }, func(c *counts) {
+		if c.Counts == nil {
+			c.Counts = map[string]int{}
+		}
+	}), path
+}
+
+func increment(key string) func(*counts) bool {
+	return func(c *counts) bool {
+		c.Counts[key]++
+		return true
+	}
+}
+
+func TestUpdateKeepsChangesOfOtherInstances(t *testing.T) {
+	store, path := newStore(t)
+	other := New(func() string { return path }, store.init)
+
+	if err := store.Update(increment("a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Update(increment("b")); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Update(increment("a")); err != nil {
+		t.Fatal(err)
+	}
+
+	var got counts
+	other.Read(func(c *counts) { got = *c })
+	if got.Counts["a"] != 2 || got.Counts["b"] != 1 {
+		t.Errorf("got %v, want a: 2 and b: 1", got.Counts)
+	}
+}
+
+func TestUpdateRefusesBrokenFile(t *testing.T) {
+	store, path := newStore(t)
+	broken := []byte(`{"counts": {"a": 1`)
+	if err := os.WriteFile(path, broken, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store.Read(func(c *counts) {
+		if len(c.Counts) != 0 {
+			t.Errorf("read %v from a broken file", c.Counts)
+		}
+	})
+	if err := store.Update(increment("b")); err == nil {
+		t.Error("updated a broken file")
+	}
+	if content, _ := os.ReadFile(path); string(content) != string(broken) {
+		t.Errorf("the broken file was overwritten with %s", content)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"counts": {"a": 1}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Update(increment("b")); err != nil {
+		t.Errorf("the fixed file was not updated: %v", err)
+	}
+}
+
+func TestRevisionChangesWithTheFile(t *testing.T) {
+	store, path := newStore(t)
+	first := store.Revision()
+	if store.Revision() != first {
+		t.Error("the revision changed without any change")
+	}
+
+	if err := os.WriteFile(path, []byte(`{"counts": {"a": 1}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if store.Revision() == first {
+		t.Error("the revision did not change with the file")
+	}
+}
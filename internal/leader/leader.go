@@ -0,0 +1,183 @@
+package leader
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/FGasquez/alum-bot/internal/filelock"
+	"github.com/sirupsen/logrus"
+)
+
+// lease is the content of the lock file
+type lease struct {
+	Owner   string    `json:"owner"`
+	Expires time.Time `json:"expires"`
+}
+
+// Elector elects one leader among the instances sharing a lock file. The
+// leader renews its lease periodically, when it stops doing so for a whole
+// lease another instance takes over.
+type Elector struct {
+	// Path of the lock file, when empty every instance is the leader
+	Path  string
+	ID    string
+	Lease time.Duration
+}
+
+// New returns an elector identified by the host name and process ID
+func New(path string, lease time.Duration) *Elector {
+	host, _ := os.Hostname()
+	return &Elector{
+		Path:  path,
+		ID:    fmt.Sprintf("%s-%d", host, os.Getpid()),
+		Lease: lease,
+	}
+}
+
+// Run runs task while this instance is the leader, until ctx is cancelled.
+// The task context is cancelled when the leadership is lost, and the task
+// runs again if it is won back.
+func (e *Elector) Run(ctx context.Context, task func(ctx context.Context) error) error {
+	if e.Path == "" {
+		return task(ctx)
+	}
+
+	log := logrus.WithFields(logrus.Fields{"lock": e.Path, "id": e.ID})
+	ticker := time.NewTicker(e.Lease / 3)
+	defer ticker.Stop()
+
+	var running *runningTask
+	for {
+		leading, err := e.acquire()
+		if err != nil {
+			log.WithError(err).Warn("Failed to acquire leader lock")
+		}
+
+		switch {
+		case leading && running == nil:
+			log.Info("Elected leader")
+			running = start(ctx, task)
+		case !leading && running != nil:
+			log.Warn("Lost leadership")
+			if err := running.stop(); err != nil {
+				return err
+			}
+			running = nil
+		}
+
+		var done <-chan error
+		if running != nil {
+			done = running.done
+		}
+
+		select {
+		case <-ctx.Done():
+			var err error
+			if running != nil {
+				err = running.stop()
+			}
+			e.release()
+			return err
+		case err := <-done:
+			// the task stopped by itself, before ctx was cancelled
+			running.cancel()
+			e.release()
+			return err
+		case <-ticker.C:
+		}
+	}
+}
+
+type runningTask struct {
+	cancel context.CancelFunc
+	done   chan error
+}
+
+func start(ctx context.Context, task func(ctx context.Context) error) *runningTask {
+	ctx, cancel := context.WithCancel(ctx)
+	running := &runningTask{cancel: cancel, done: make(chan error, 1)}
+	go func() { running.done <- task(ctx) }()
+	return running
+}
+
+// stop cancels the task and waits for it
+func (r *runningTask) stop() error {
+	r.cancel()
+	return <-r.done
+}
+
+// acquire takes or renews the lease, reporting whether this instance holds it.
+// The lease is read and written holding the guard lock, so two instances never
+// both take an expired lease.
+func (e *Elector) acquire() (bool, error) {
+	unlock, err := filelock.Lock(e.guardPath())
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	current, err := e.read()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+	if err == nil && current.Owner != e.ID && time.Now().Before(current.Expires) {
+		return false, nil
+	}
+
+	if err := e.write(lease{Owner: e.ID, Expires: time.Now().Add(e.Lease)}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// release removes the lock when this instance holds it, so another one takes over right away
+func (e *Elector) release() {
+	unlock, err := filelock.Lock(e.guardPath())
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to release leader lock")
+		return
+	}
+	defer unlock()
+
+	if current, err := e.read(); err == nil && current.Owner == e.ID {
+		if err := os.Remove(e.Path); err != nil {
+			logrus.WithError(err).Warn("Failed to release leader lock")
+		}
+	}
+}
+
+// guardPath is the file locked while the lease is read and written
+func (e *Elector) guardPath() string {
+	return e.Path + ".guard"
+}
+
+func (e *Elector) read() (lease, error) {
+	var current lease
+	data, err := os.ReadFile(e.Path)
+	if err != nil {
+		return current, err
+	}
+	err = json.Unmarshal(data, &current)
+	return current, err
+}
+
+func (e *Elector) write(l lease) error {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(e.Path), 0755); err != nil {
+		return err
+	}
+
+	tmp := fmt.Sprintf("%s.%s.tmp", e.Path, e.ID)
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, e.Path)
+}
@@ -0,0 +1,59 @@
+package leader
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAcquireElectsOneLeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leader.lock")
+
+	var wg sync.WaitGroup
+	leaders := make(chan string, 20)
+	for n := range 20 {
+		e := &Elector{Path: path, ID: string(rune('a' + n)), Lease: time.Minute}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			leading, err := e.acquire()
+			if err != nil {
+				t.Error(err)
+			}
+			if leading {
+				leaders <- e.ID
+			}
+		}()
+	}
+	wg.Wait()
+	close(leaders)
+
+	var elected []string
+	for id := range leaders {
+		elected = append(elected, id)
+	}
+	if len(elected) != 1 {
+		t.Errorf("elected %v, want one leader", elected)
+	}
+}
+
+func TestAcquireTakesExpiredLease(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leader.lock")
+	first := &Elector{Path: path, ID: "first", Lease: time.Minute}
+	second := &Elector{Path: path, ID: "second", Lease: time.Minute}
+
+	if leading, err := first.acquire(); err != nil || !leading {
+		t.Fatalf("first acquire = %v, %v", leading, err)
+	}
+	if leading, _ := second.acquire(); leading {
+		t.Fatal("the second instance took a live lease")
+	}
+
+	if err := first.write(lease{Owner: first.ID, Expires: time.Now().Add(-time.Second)}); err != nil {
+		t.Fatal(err)
+	}
+	if leading, err := second.acquire(); err != nil || !leading {
+		t.Errorf("second acquire of an expired lease = %v, %v", leading, err)
+	}
+}
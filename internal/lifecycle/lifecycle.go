@@ -0,0 +1,89 @@
+package lifecycle
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+)
+
+// Manager runs the background workers of the bot under a root context and
+// keeps count of the interactions in flight. The first worker failing cancels
+// the context, stopping all the others.
+type Manager struct {
+	group    *errgroup.Group
+	ctx      context.Context
+	cancel   context.CancelFunc
+	inflight sync.WaitGroup
+	// mu guards draining, so no interaction is tracked once Drain waits
+	mu       sync.Mutex
+	draining bool
+}
+
+// New returns a manager whose context is cancelled with parent, by Stop or
+// when a worker fails
+func New(parent context.Context) (*Manager, context.Context) {
+	ctx, cancel := context.WithCancel(parent)
+	group, ctx := errgroup.WithContext(ctx)
+	return &Manager{group: group, ctx: ctx, cancel: cancel}, ctx
+}
+
+// Stop cancels the context of the workers
+func (m *Manager) Stop() {
+	m.cancel()
+}
+
+// Go runs a worker until the context is cancelled, a non-nil error stops the bot
+func (m *Manager) Go(name string, worker func(ctx context.Context) error) {
+	m.group.Go(func() error {
+		log := logrus.WithField("worker", name)
+		log.Debug("Starting worker")
+		err := worker(m.ctx)
+		if err != nil {
+			log.WithError(err).Error("Worker failed")
+		} else {
+			log.Debug("Worker stopped")
+		}
+		return err
+	})
+}
+
+// Track counts an interaction in flight until the returned function is
+// called. It reports false, tracking nothing, once Drain started.
+func (m *Manager) Track() (done func(), ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.draining {
+		return nil, false
+	}
+	m.inflight.Add(1)
+	return m.inflight.Done, true
+}
+
+// Wait waits for every worker to stop, returning the first error
+func (m *Manager) Wait() error {
+	defer m.cancel()
+	return m.group.Wait()
+}
+
+// Drain waits up to timeout for the interactions in flight, reporting whether they all finished
+func (m *Manager) Drain(timeout time.Duration) bool {
+	m.mu.Lock()
+	m.draining = true
+	m.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		m.inflight.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
@@ -0,0 +1,50 @@
+package lifecycle
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDrainWaitsForTrackedInteractions(t *testing.T) {
+	manager, _ := New(context.Background())
+	done, ok := manager.Track()
+	if !ok {
+		t.Fatal("an interaction was refused before draining")
+	}
+
+	if manager.Drain(10 * time.Millisecond) {
+		t.Error("drained with an interaction in flight")
+	}
+	done()
+	if !manager.Drain(time.Second) {
+		t.Error("not drained after the interaction finished")
+	}
+}
+
+func TestTrackRefusedWhileDraining(t *testing.T) {
+	manager, _ := New(context.Background())
+	done, _ := manager.Track()
+
+	drained := make(chan bool)
+	go func() { drained <- manager.Drain(time.Second) }()
+
+	// wait for Drain to start, then new interactions are refused
+	deadline := time.Now().Add(time.Second)
+	for {
+		early, ok := manager.Track()
+		if !ok {
+			break
+		}
+		early()
+		if time.Now().After(deadline) {
+			t.Fatal("interactions still tracked while draining")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	done()
+	if !<-drained {
+		t.Error("not drained")
+	}
+}
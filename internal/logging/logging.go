@@ -0,0 +1,111 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/sirupsen/logrus"
+)
+
+type loggerKey struct{}
+
+// Configure sets the level and format of the standard logger, format is json or text
+func Configure(level string, format string, out io.Writer) error {
+	parsed, err := logrus.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(format) {
+	case "json":
+		logrus.SetFormatter(redactor{&logrus.JSONFormatter{}})
+	case "text":
+		logrus.SetFormatter(redactor{&logrus.TextFormatter{FullTimestamp: true}})
+	default:
+		return fmt.Errorf("unknown log format %q, use json or text", format)
+	}
+
+	logrus.SetLevel(parsed)
+	logrus.SetOutput(out)
+	return nil
+}
+
+// minSecretLength keeps short values, that are not real secrets, from garbling the logs
+const minSecretLength = 8
+
+// Redact hides a secret from every log line written after a call to Configure
+func Redact(secret string) {
+	if len(secret) < minSecretLength {
+		return
+	}
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	secrets = append(secrets, []byte(secret))
+}
+
+var (
+	secretsMu sync.RWMutex
+	secrets   [][]byte
+)
+
+// redactor replaces the secrets in the lines of a formatter, fields and errors included
+type redactor struct {
+	logrus.Formatter
+}
+
+func (r redactor) Format(entry *logrus.Entry) ([]byte, error) {
+	line, err := r.Formatter.Format(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+	for _, secret := range secrets {
+		line = bytes.ReplaceAll(line, secret, []byte("<redacted>"))
+	}
+	return line, nil
+}
+
+// WithLogger returns a copy of ctx carrying the logger
+func WithLogger(ctx context.Context, log *logrus.Entry) context.Context {
+	return context.WithValue(ctx, loggerKey{}, log)
+}
+
+// FromContext returns the logger carried by ctx, or the standard logger
+func FromContext(ctx context.Context) *logrus.Entry {
+	if log, ok := ctx.Value(loggerKey{}).(*logrus.Entry); ok {
+		return log
+	}
+	return logrus.NewEntry(logrus.StandardLogger())
+}
+
+// ForInteraction returns a logger with the interaction ID, command or modal, guild and user
+func ForInteraction(i *discordgo.InteractionCreate) *logrus.Entry {
+	fields := logrus.Fields{"interaction": i.ID}
+	if i.Type == discordgo.InteractionApplicationCommand || i.Type == discordgo.InteractionApplicationCommandAutocomplete {
+		fields["command"] = i.ApplicationCommandData().Name
+	}
+	if i.Type == discordgo.InteractionModalSubmit {
+		fields["modal"] = i.ModalSubmitData().CustomID
+	}
+	if i.GuildID != "" {
+		fields["guild"] = i.GuildID
+	}
+	if i.Member != nil && i.Member.User != nil {
+		fields["user"] = i.Member.User.ID
+	} else if i.User != nil {
+		fields["user"] = i.User.ID
+	}
+	return logrus.WithFields(fields)
+}
+
+// Interaction returns a copy of ctx carrying the logger of the interaction
+func Interaction(ctx context.Context, i *discordgo.InteractionCreate) context.Context {
+	return WithLogger(ctx, ForInteraction(i))
+}
@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestRedact(t *testing.T) {
+	const secret = "MTIzNDU2Nzg5MDEyMzQ1Njc4.GhJk1a.abcdefghijklmnopqrstuvwxyz"
+	Redact(secret)
+	Redact("short")
+
+	logger := logrus.StandardLogger()
+	formatter, out, level := logger.Formatter, logger.Out, logger.Level
+	t.Cleanup(func() {
+		logger.SetFormatter(formatter)
+		logger.SetOutput(out)
+		logger.SetLevel(level)
+	})
+
+	for _, format := range []string{"json", "text"} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Configure("info", format, &buf); err != nil {
+				t.Fatal(err)
+			}
+
+			logrus.Infof("connecting with %s", secret)
+			logrus.WithField("token", secret).Info("field")
+			logrus.WithError(errors.New("bad token " + secret)).Error("error")
+			logrus.WithField("word", "short").Info("short values stay")
+
+			lines := buf.String()
+			if strings.Contains(lines, secret) {
+				t.Errorf("the token shows in %s", lines)
+			}
+			if got := strings.Count(lines, "<redacted>"); got != 3 {
+				t.Errorf("%d redactions, want 3 in %s", got, lines)
+			}
+			if !strings.Contains(lines, "short") {
+				t.Errorf("short values were redacted in %s", lines)
+			}
+		})
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	logger := logrus.StandardLogger()
+	formatter, out, level := logger.Formatter, logger.Out, logger.Level
+	t.Cleanup(func() {
+		logger.SetFormatter(formatter)
+		logger.SetOutput(out)
+		logger.SetLevel(level)
+	})
+
+	var buf bytes.Buffer
+	if err := Configure("loud", "json", &buf); err == nil {
+		t.Error("an unknown level was accepted")
+	}
+	if err := Configure("info", "xml", &buf); err == nil {
+		t.Error("an unknown format was accepted")
+	}
+}
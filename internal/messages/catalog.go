@@ -0,0 +1,54 @@
+package messages
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+type cachedCatalog struct {
+	messages map[string]string
+	err      error
+	modTime  time.Time
+	size     int64
+}
+
+var (
+	catalogsMu   sync.Mutex
+	catalogCache = map[string]cachedCatalog{}
+)
+
+// loadCatalog returns the messages of a yaml file. The parsed files are kept
+// until their modification time or size changes, so rendering a message does
+// not read them again. The returned map must not be changed.
+func loadCatalog(path string) (map[string]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	catalogsMu.Lock()
+	cached, ok := catalogCache[path]
+	catalogsMu.Unlock()
+	if ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.messages, cached.err
+	}
+
+	cached = cachedCatalog{modTime: info.ModTime(), size: info.Size()}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if cached.err = yaml.Unmarshal(data, &cached.messages); cached.err != nil {
+		cached.messages = nil
+		logrus.WithError(cached.err).WithField("path", path).Warn("Failed to parse messages catalog")
+	}
+
+	catalogsMu.Lock()
+	catalogCache[path] = cached
+	catalogsMu.Unlock()
+	return cached.messages, cached.err
+}
@@ -0,0 +1,60 @@
+package messages
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadCatalog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "es.yaml")
+	modTime := time.Now().Add(-time.Hour)
+	write := func(content string, modTime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	load := func(want string) {
+		t.Helper()
+		messages, err := loadCatalog(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := messages["nextHoliday"]; got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+
+	if _, err := loadCatalog(path); !os.IsNotExist(err) {
+		t.Fatalf("got %v, want a missing file", err)
+	}
+
+	write("nextHoliday: uno\n", modTime)
+	load("uno")
+
+	// same size and modification time, the cached messages are kept
+	write("nextHoliday: dos\n", modTime)
+	load("uno")
+
+	write("nextHoliday: dos\n", modTime.Add(time.Second))
+	load("dos")
+
+	write("nextHoliday: tres!\n", modTime.Add(time.Second))
+	load("tres!")
+
+	write("nextHoliday: [\n", modTime.Add(2*time.Second))
+	if messages, err := loadCatalog(path); err == nil || messages != nil {
+		t.Errorf("got %v, %v, want a parse error", messages, err)
+	}
+	if _, err := loadCatalog(path); err == nil {
+		t.Error("the cached parse error was lost")
+	}
+
+	write("nextHoliday: cuatro\n", modTime.Add(3*time.Second))
+	load("cuatro")
+}
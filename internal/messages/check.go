@@ -0,0 +1,273 @@
+package messages
+
+import (
+	"fmt"
+	"reflect"
+	"text/template"
+	"text/template/parse"
+)
+
+var (
+	contextType = reflect.TypeOf(Context{})
+	boolType    = reflect.TypeOf(true)
+	intType     = reflect.TypeOf(0)
+	stringType  = reflect.TypeOf("")
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// checkFields reports the first field of a template missing in Context,
+// following the dot through with and range. Unlike rendering, it also
+// checks the branches the sample context does not take.
+func checkFields(tmpl *template.Template) error {
+	c := &checker{tree: tmpl.Tree, vars: map[string]reflect.Type{"$": contextType}}
+	return c.list(tmpl.Tree.Root, contextType)
+}
+
+// checker walks a template tracking the type of the dot and the variables,
+// a nil type is unknown and is not checked further
+type checker struct {
+	tree *parse.Tree
+	vars map[string]reflect.Type
+}
+
+func (c *checker) errorf(node parse.Node, format string, args ...interface{}) error {
+	location, _ := c.tree.ErrorContext(node)
+	return fmt.Errorf("%s: %s", location, fmt.Sprintf(format, args...))
+}
+
+func (c *checker) list(list *parse.ListNode, dot reflect.Type) error {
+	if list == nil {
+		return nil
+	}
+	for _, node := range list.Nodes {
+		if err := c.node(node, dot); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *checker) node(node parse.Node, dot reflect.Type) error {
+	switch n := node.(type) {
+	case *parse.ActionNode:
+		_, err := c.pipe(n.Pipe, dot)
+		return err
+	case *parse.IfNode:
+		return c.branch(&n.BranchNode, dot, false)
+	case *parse.WithNode:
+		return c.branch(&n.BranchNode, dot, true)
+	case *parse.RangeNode:
+		t, err := c.pipe(n.Pipe, dot)
+		if err != nil {
+			return err
+		}
+		key, elem := rangeTypes(t)
+		switch len(n.Pipe.Decl) {
+		case 1:
+			c.vars[n.Pipe.Decl[0].Ident[0]] = elem
+		case 2:
+			c.vars[n.Pipe.Decl[0].Ident[0]] = key
+			c.vars[n.Pipe.Decl[1].Ident[0]] = elem
+		}
+		if err := c.list(n.List, elem); err != nil {
+			return err
+		}
+		return c.list(n.ElseList, dot)
+	}
+	return nil
+}
+
+// branch checks an if or a with, with moves the dot to the value of its pipeline
+func (c *checker) branch(n *parse.BranchNode, dot reflect.Type, with bool) error {
+	t, err := c.pipe(n.Pipe, dot)
+	if err != nil {
+		return err
+	}
+	inner := dot
+	if with {
+		inner = t
+	}
+	if err := c.list(n.List, inner); err != nil {
+		return err
+	}
+	return c.list(n.ElseList, dot)
+}
+
+// pipe checks a pipeline and returns the type of its value
+func (c *checker) pipe(pipe *parse.PipeNode, dot reflect.Type) (reflect.Type, error) {
+	if pipe == nil {
+		return nil, nil
+	}
+	var t reflect.Type
+	for i, cmd := range pipe.Cmds {
+		var err error
+		// the value of the previous command is the last argument of the next one
+		t, err = c.command(cmd, dot, i > 0, t)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, variable := range pipe.Decl {
+		c.vars[variable.Ident[0]] = t
+	}
+	return t, nil
+}
+
+func (c *checker) command(cmd *parse.CommandNode, dot reflect.Type, piped bool, previous reflect.Type) (reflect.Type, error) {
+	args := make([]reflect.Type, 0, len(cmd.Args))
+	for _, arg := range cmd.Args[1:] {
+		t, err := c.arg(arg, dot)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, t)
+	}
+	if piped {
+		args = append(args, previous)
+	}
+
+	if ident, ok := cmd.Args[0].(*parse.IdentifierNode); ok {
+		return c.function(cmd, ident.Ident, args)
+	}
+	return c.arg(cmd.Args[0], dot)
+}
+
+// arg returns the type of an operand
+func (c *checker) arg(node parse.Node, dot reflect.Type) (reflect.Type, error) {
+	switch n := node.(type) {
+	case *parse.DotNode:
+		return dot, nil
+	case *parse.FieldNode:
+		return c.fields(n, dot, n.Ident)
+	case *parse.VariableNode:
+		t, ok := c.vars[n.Ident[0]]
+		if !ok {
+			return nil, c.errorf(n, "undefined variable %s", n.Ident[0])
+		}
+		return c.fields(n, t, n.Ident[1:])
+	case *parse.ChainNode:
+		t, err := c.arg(n.Node, dot)
+		if err != nil {
+			return nil, err
+		}
+		return c.fields(n, t, n.Field)
+	case *parse.PipeNode:
+		return c.pipe(n, dot)
+	case *parse.IdentifierNode:
+		return c.function(nil, n.Ident, nil)
+	case *parse.StringNode:
+		return stringType, nil
+	case *parse.BoolNode:
+		return boolType, nil
+	}
+	// numbers are untyped until used, nil has no type
+	return nil, nil
+}
+
+// fields follows a chain of field names from t
+func (c *checker) fields(node parse.Node, t reflect.Type, names []string) (reflect.Type, error) {
+	for _, name := range names {
+		if t == nil {
+			return nil, nil
+		}
+
+		if method, ok := t.MethodByName(name); ok {
+			t = result(method.Type)
+			continue
+		}
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		switch t.Kind() {
+		case reflect.Struct:
+			field, ok := t.FieldByName(name)
+			if !ok || !field.IsExported() {
+				return nil, c.errorf(node, "can't evaluate field %s in type %s", name, t)
+			}
+			t = field.Type
+		case reflect.Map:
+			t = t.Elem()
+		case reflect.Interface:
+			return nil, nil
+		default:
+			return nil, c.errorf(node, "can't evaluate field %s in type %s", name, t)
+		}
+	}
+	return t, nil
+}
+
+// function returns the type of the value of a function, checking index on known types
+func (c *checker) function(cmd *parse.CommandNode, name string, args []reflect.Type) (reflect.Type, error) {
+	switch name {
+	case "and", "or", "call":
+		return nil, nil
+	case "not", "eq", "ne", "lt", "le", "gt", "ge":
+		return boolType, nil
+	case "len":
+		return intType, nil
+	case "print", "printf", "println", "html", "js", "urlquery":
+		return stringType, nil
+	case "slice":
+		if len(args) > 0 {
+			return args[0], nil
+		}
+		return nil, nil
+	case "index":
+		if len(args) == 0 {
+			return nil, nil
+		}
+		t := args[0]
+		for range args[1:] {
+			if t == nil {
+				return nil, nil
+			}
+			for t.Kind() == reflect.Pointer {
+				t = t.Elem()
+			}
+			switch t.Kind() {
+			case reflect.Slice, reflect.Array, reflect.Map:
+				t = t.Elem()
+			case reflect.Interface:
+				return nil, nil
+			default:
+				return nil, c.errorf(cmd, "can't index item of type %s", t)
+			}
+		}
+		return t, nil
+	}
+
+	if fn, ok := funcMap[name]; ok {
+		return result(reflect.TypeOf(fn)), nil
+	}
+	// the parser already rejects undefined functions
+	return nil, nil
+}
+
+// result returns the type of the value returned by a function type
+func result(fn reflect.Type) reflect.Type {
+	if fn.NumOut() == 0 || fn.Out(0) == errorType {
+		return nil
+	}
+	return fn.Out(0)
+}
+
+// rangeTypes returns the types of the key and element of a range over t
+func rangeTypes(t reflect.Type) (key reflect.Type, elem reflect.Type) {
+	if t == nil {
+		return nil, nil
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return intType, t.Elem()
+	case reflect.Map:
+		return t.Key(), t.Elem()
+	case reflect.Int, reflect.Int64:
+		return t, t
+	case reflect.Chan:
+		return nil, t.Elem()
+	}
+	return nil, nil
+}
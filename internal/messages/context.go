@@ -0,0 +1,215 @@
+package messages
+
+import (
+	"context"
+	"time"
+
+	"github.com/FGasquez/alum-bot/internal/clock"
+	"github.com/FGasquez/alum-bot/internal/helpers"
+	"github.com/FGasquez/alum-bot/internal/types"
+)
+
+// Context is the data every message is rendered with, built with NewContext.
+// Its fields are grouped by the messages using them, and promoted so templates
+// read them directly, e.g. .HolidayName. The fields that do not apply to a
+// message are left empty.
+type Context struct {
+	// GuildID and UserID the message is for, GuildID is empty in DMs
+	GuildID string
+	UserID  string
+	// Locale of the user, e.g. es-ES, empty when unknown
+	Locale string
+	// Now is the time the message is rendered at
+	Now time.Time
+
+	HolidayData
+	LongWeekendData
+	CalendarData
+	SettingsData
+	ReminderData
+	TemplateData
+}
+
+// HolidayData is the holiday a message is about
+type HolidayData struct {
+	// Holiday is nil when there is none
+	Holiday       *types.ParsedHolidays
+	HolidayName   string
+	DaysLeft      int
+	FormattedDate string
+	NamedDate     types.NamedDate
+	RawDate       types.RawDate
+	FullDate      string
+	IsToday       bool
+	// Adjacents are the days off around the holiday, or every day of the long weekend
+	Adjacents []types.ParsedHolidays
+	// Description, History and Link tell about the holiday, empty when it is
+	// not in the holiday information
+	Description string
+	History     string
+	Link        string
+	// TodayHoliday is the name of today's holiday, empty when today is not one
+	TodayHoliday string
+}
+
+// LongWeekendData is the long weekend a message is about
+type LongWeekendData struct {
+	// LongWeekend is nil when there is none
+	LongWeekend *types.LongWeekend
+	// Length and HolidayList are the days off and holidays of LongWeekend
+	Length      int
+	HolidayList []types.ParsedHolidays
+}
+
+// CalendarData are the holidays of a month or the long weekends of a year
+type CalendarData struct {
+	Month string
+	Year  int
+	// Count of the holidays of the month, or of the long weekends of the year
+	Count        int
+	HolidaysList []types.ParsedHolidays
+	LongWeekends []types.LongWeekend
+	// MinDays is the minimum length of the long weekends, or of those of a reminder
+	MinDays int
+}
+
+// SettingsData describes a setting of a guild or a user
+type SettingsData struct {
+	// Guild is set when a setting was changed for the whole server
+	Guild          bool
+	Country        string
+	UserCountry    string
+	GuildCountry   string
+	DefaultCountry string
+	Countries      []string
+	Region         string
+	UserRegion     string
+	GuildRegion    string
+	Regions        []Region
+	Private        bool
+	// Date and Name of a custom holiday or a reminder
+	Date           string
+	Name           string
+	CustomHolidays []CustomHoliday
+}
+
+// ReminderData describes a reminder, its Date and MinDays are in
+// SettingsData and CalendarData
+type ReminderData struct {
+	ID         string
+	Kind       string
+	DaysBefore int
+	// ChannelID is empty for reminders sent by DM
+	ChannelID string
+	Reminders []Reminder
+	// Mention of the user in reminders sent to a channel, empty in DMs
+	Mention string
+}
+
+// TemplateData describes a message template being edited
+type TemplateData struct {
+	Key     string
+	Preview string
+	Error   string
+}
+
+// Region is a region of a country, listed by the region command
+type Region struct {
+	Code    string
+	Name    string
+	Country string
+}
+
+// CustomHoliday is a day off of a guild, Date is yyyy-mm-dd or mm-dd
+type CustomHoliday struct {
+	Date string
+	Name string
+}
+
+// Reminder is a reminder of a user, Date is set for the reminders of one
+// holiday and MinDays for those of long weekends
+type Reminder struct {
+	ID         string
+	Kind       string
+	DaysBefore int
+	Date       string
+	MinDays    int
+	ChannelID  string
+	Country    string
+	Region     string
+}
+
+// ContextOption fills the fields of a Context derived from one value
+type ContextOption func(c *Context)
+
+// NewContext returns the context of a message in the scope carried by ctx
+func NewContext(ctx context.Context, options ...ContextOption) Context {
+	scope := ScopeFromContext(ctx)
+	c := Context{
+		GuildID: scope.GuildID,
+		UserID:  scope.UserID,
+		Locale:  scope.Locale,
+		Now:     clock.Now(),
+	}
+	for _, option := range options {
+		option(&c)
+	}
+	return c
+}
+
+// Holiday sets the holiday the message is about, daysLeft away
+func Holiday(holiday types.ParsedHolidays, daysLeft int) ContextOption {
+	return func(c *Context) {
+		c.Holiday = &holiday
+		c.HolidayName = holiday.Name
+		c.DaysLeft = daysLeft
+		c.FullDate = holiday.Date
+		c.IsToday = holiday.IsToday || daysLeft == 0
+		c.Adjacents = holiday.Adjacent
+
+		parsedDate, err := time.Parse("2006-01-02", holiday.Date)
+		if err != nil {
+			return
+		}
+		formatted, day, month, _ := helpers.FormatDateToSpanish(parsedDate)
+		c.FormattedDate = formatted
+		c.NamedDate = types.NamedDate{Day: day, Month: month}
+		c.RawDate = types.RawDate{
+			Day:   parsedDate.Day(),
+			Month: int(parsedDate.Month()),
+			Year:  parsedDate.Year(),
+		}
+	}
+}
+
+// LongWeekend sets the long weekend the message is about, nil when there is none
+func LongWeekend(longWeekend *types.LongWeekend) ContextOption {
+	return func(c *Context) {
+		c.LongWeekend = longWeekend
+		if longWeekend == nil {
+			return
+		}
+		c.Length = longWeekend.Length
+		c.HolidayList = longWeekend.Holidays
+		c.Adjacents = longWeekend.Days
+	}
+}
+
+// Month sets the month the message is about and its holidays
+func Month(name string, holidays []types.ParsedHolidays) ContextOption {
+	return func(c *Context) {
+		c.Month = name
+		c.Count = len(holidays)
+		c.HolidaysList = holidays
+	}
+}
+
+// LongWeekends sets the long weekends of a year at least minDays long
+func LongWeekends(year int, minDays int, longWeekends []types.LongWeekend) ContextOption {
+	return func(c *Context) {
+		c.Year = year
+		c.MinDays = minDays
+		c.Count = len(longWeekends)
+		c.LongWeekends = longWeekends
+	}
+}
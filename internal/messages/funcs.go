@@ -0,0 +1,130 @@
+package messages
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/FGasquez/alum-bot/internal/clock"
+	"github.com/FGasquez/alum-bot/internal/helpers"
+	"github.com/FGasquez/alum-bot/internal/types"
+)
+
+const dateLayout = "2006-01-02"
+
+// typeEmojis are the emojis of the holiday types, see emoji
+var typeEmojis = map[string]string{
+	"inamovible":     "🇦🇷",
+	"trasladable":    "📆",
+	"nolaborable":    "🏖️",
+	types.Bridge:     "🌉",
+	types.Weekend:    "😴",
+	types.Provincial: "🏛️",
+	types.Custom:     "⭐",
+}
+
+// defaultEmoji is the emoji of the holiday types missing in typeEmojis
+const defaultEmoji = "🎉"
+
+// funcMap are the functions available to every message template
+var funcMap = template.FuncMap{
+	"add":        func(a, b int) int { return a + b },
+	"sub":        func(a, b int) int { return a - b },
+	"formatDate": helpers.FormatDate,
+	"pluralize":  pluralize,
+	"relative":   helpers.RelativeDays,
+	"timestamp":  timestamp,
+	"weekday":    helpers.Weekday,
+	"addDays":    addDays,
+	"daysUntil":  daysUntil,
+	"join":       join,
+	"names":      names,
+	"upper":      strings.ToUpper,
+	"lower":      strings.ToLower,
+	"emoji":      emoji,
+	"random":     random,
+}
+
+// pluralize returns singular when n is 1 or -1, and plural otherwise
+func pluralize(n int, singular string, plural string) string {
+	if n == 1 || n == -1 {
+		return singular
+	}
+	return plural
+}
+
+// timestamp returns the Discord markup showing a yyyy-mm-dd date, at local
+// midnight, in the time zone of each user. The style is one of t, T, d, D, f,
+// F or R, e.g. R shows "in 2 days".
+func timestamp(date string, style string) (string, error) {
+	t, err := time.ParseInLocation(dateLayout, date, time.Local)
+	if err != nil {
+		return "", err
+	}
+	if !strings.Contains("tTdDfFR", style) || len(style) != 1 {
+		return "", fmt.Errorf("unknown timestamp style %q, use one of t, T, d, D, f, F or R", style)
+	}
+	return fmt.Sprintf("<t:%d:%s>", t.Unix(), style), nil
+}
+
+// addDays returns the yyyy-mm-dd date days after date, days may be negative
+func addDays(date string, days int) (string, error) {
+	t, err := time.Parse(dateLayout, date)
+	if err != nil {
+		return "", err
+	}
+	return t.AddDate(0, 0, days).Format(dateLayout), nil
+}
+
+// daysUntil returns the calendar days from today to a yyyy-mm-dd date, negative when it passed
+func daysUntil(date string) (int, error) {
+	t, err := time.Parse(dateLayout, date)
+	if err != nil {
+		return 0, err
+	}
+	now := clock.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return int(t.Sub(today).Hours() / 24), nil
+}
+
+// join joins the elements of a list with sep, it takes the list last so it can be piped
+func join(sep string, list interface{}) (string, error) {
+	value := reflect.ValueOf(list)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return "", fmt.Errorf("join: %T is not a list", list)
+	}
+
+	parts := make([]string, value.Len())
+	for i := range parts {
+		parts[i] = fmt.Sprint(value.Index(i).Interface())
+	}
+	return strings.Join(parts, sep), nil
+}
+
+// names returns the names of a list of holidays
+func names(holidays []types.ParsedHolidays) []string {
+	result := make([]string, len(holidays))
+	for i, holiday := range holidays {
+		result[i] = holiday.Name
+	}
+	return result
+}
+
+// emoji returns the emoji of a holiday type
+func emoji(holidayType string) string {
+	if e, ok := typeEmojis[holidayType]; ok {
+		return e
+	}
+	return defaultEmoji
+}
+
+// random returns one of its arguments at random, to vary the phrasing of a message
+func random(choices ...string) string {
+	if len(choices) == 0 {
+		return ""
+	}
+	return choices[rand.Intn(len(choices))]
+}
@@ -0,0 +1,100 @@
+package messages
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/FGasquez/alum-bot/internal/clock"
+)
+
+func TestPluralize(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{n: 1, want: "día"},
+		{n: -1, want: "día"},
+		{n: 0, want: "días"},
+		{n: 2, want: "días"},
+		{n: -3, want: "días"},
+	}
+
+	for _, test := range tests {
+		if got := pluralize(test.n, "día", "días"); got != test.want {
+			t.Errorf("pluralize(%d) = %q, want %q", test.n, got, test.want)
+		}
+	}
+}
+
+// TestRelative renders relative the way the templates use it, on the days
+// until a date, with the clock fixed on 2025-07-01
+func TestRelative(t *testing.T) {
+	clock.Set(clock.Fixed(time.Date(2025, 7, 1, 10, 0, 0, 0, time.Local)))
+	defer clock.Set(nil)
+
+	tests := []struct {
+		name string
+		date string
+		want string
+	}{
+		{name: "long ago", date: "2025-04-01", want: "hace 3 meses"},
+		{name: "weeks ago", date: "2025-06-10", want: "hace 3 semanas"},
+		{name: "days ago", date: "2025-06-26", want: "hace 5 días"},
+		{name: "day before yesterday", date: "2025-06-29", want: "anteayer"},
+		{name: "yesterday", date: "2025-06-30", want: "ayer"},
+		{name: "today", date: "2025-07-01", want: "hoy"},
+		{name: "tomorrow", date: "2025-07-02", want: "mañana"},
+		{name: "day after tomorrow", date: "2025-07-03", want: "pasado mañana"},
+		{name: "days ahead", date: "2025-07-09", want: "en 8 días"},
+		{name: "weeks ahead", date: "2025-08-15", want: "en 6 semanas"},
+		{name: "months ahead", date: "2025-12-25", want: "en 6 meses"},
+	}
+
+	tmpl := template.Must(template.New("relative").Funcs(funcMap).Parse("{{ relative (daysUntil .) }}"))
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got strings.Builder
+			if err := tmpl.Execute(&got, test.date); err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != test.want {
+				t.Errorf("got %q, want %q", got.String(), test.want)
+			}
+		})
+	}
+}
+
+func TestTimestamp(t *testing.T) {
+	unix := time.Date(2025, 7, 9, 0, 0, 0, 0, time.Local).Unix()
+
+	for _, style := range []string{"t", "T", "d", "D", "f", "F", "R"} {
+		t.Run(style, func(t *testing.T) {
+			got, err := timestamp("2025-07-09", style)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := fmt.Sprintf("<t:%d:%s>", unix, style); got != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+
+	invalid := []struct {
+		name, date, style string
+	}{
+		{name: "unknown style", date: "2025-07-09", style: "x"},
+		{name: "several styles", date: "2025-07-09", style: "tT"},
+		{name: "empty style", date: "2025-07-09", style: ""},
+		{name: "bad date", date: "09/07/2025", style: "R"},
+	}
+	for _, test := range invalid {
+		t.Run(test.name, func(t *testing.T) {
+			if got, err := timestamp(test.date, test.style); err == nil {
+				t.Errorf("got %q, want an error", got)
+			}
+		})
+	}
+}
@@ -2,14 +2,14 @@ package messages
 
 import (
 	"bytes"
-	"io"
-	"os"
+	"errors"
+	"io/fs"
+	"path/filepath"
 	"text/template"
 
 	"github.com/FGasquez/alum-bot/internal/config"
-	"github.com/FGasquez/alum-bot/internal/helpers"
+	"github.com/FGasquez/alum-bot/internal/metrics"
 	"github.com/sirupsen/logrus"
-	"gopkg.in/yaml.v2"
 )
 
 type MessageKeysStruct struct {
@@ -20,6 +20,49 @@ type MessageKeysStruct struct {
 	HolidaysOfMonth          string
 	NextLargeHoliday         string
 	ActivityStatus           string
+	LongWeekends             string
+	NoLongWeekends           string
+	UnknownRegion            string
+	RegionSet                string
+	RegionCleared            string
+	RegionShow               string
+	RegionList               string
+	MissingPermissions       string
+	InvalidDate              string
+	CustomHolidayAdded       string
+	CustomHolidayRemoved     string
+	CustomHolidayNotFound    string
+	CustomHolidayList        string
+	NoCustomHolidays         string
+	UnknownCountry           string
+	CountrySet               string
+	CountryCleared           string
+	CountryShow              string
+	CountryList              string
+	APITimeout               string
+	APIUnavailable           string
+	HolidaysNotFound         string
+	IsToday                  string
+	NoLargeHolidays          string
+	PrivacySet               string
+	PrivacyShow              string
+	ReminderSet              string
+	ReminderList             string
+	NoReminders              string
+	ReminderCancelled        string
+	ReminderNotFound         string
+	NotAHoliday              string
+	Reminder                 string
+	StatusTodayHoliday       string
+	StatusLongWeekend        string
+	StatusHolidaysOfMonth    string
+	MessageSet               string
+	MessageReset             string
+	UnknownMessageKey        string
+	InvalidTemplate          string
+	HolidayInfo              string
+	UnknownHoliday           string
+	FailedToSaveSettings     string
 }
 
 var MessageKeys = MessageKeysStruct{
@@ -30,67 +73,172 @@ var MessageKeys = MessageKeysStruct{
 	HolidaysOfMonth:          "holidaysOfMonth",
 	NextLargeHoliday:         "nextLargeHoliday",
 	ActivityStatus:           "activityStatus",
+	LongWeekends:             "longWeekends",
+	NoLongWeekends:           "noLongWeekends",
+	UnknownRegion:            "unknownRegion",
+	RegionSet:                "regionSet",
+	RegionCleared:            "regionCleared",
+	RegionShow:               "regionShow",
+	RegionList:               "regionList",
+	MissingPermissions:       "missingPermissions",
+	InvalidDate:              "invalidDate",
+	CustomHolidayAdded:       "customHolidayAdded",
+	CustomHolidayRemoved:     "customHolidayRemoved",
+	CustomHolidayNotFound:    "customHolidayNotFound",
+	CustomHolidayList:        "customHolidayList",
+	NoCustomHolidays:         "noCustomHolidays",
+	UnknownCountry:           "unknownCountry",
+	CountrySet:               "countrySet",
+	CountryCleared:           "countryCleared",
+	CountryShow:              "countryShow",
+	CountryList:              "countryList",
+	APITimeout:               "apiTimeout",
+	APIUnavailable:           "apiUnavailable",
+	HolidaysNotFound:         "holidaysNotFound",
+	IsToday:                  "isToday",
+	NoLargeHolidays:          "noLargeHolidays",
+	PrivacySet:               "privacySet",
+	PrivacyShow:              "privacyShow",
+	ReminderSet:              "reminderSet",
+	ReminderList:             "reminderList",
+	NoReminders:              "noReminders",
+	ReminderCancelled:        "reminderCancelled",
+	ReminderNotFound:         "reminderNotFound",
+	NotAHoliday:              "notAHoliday",
+	Reminder:                 "reminder",
+	StatusTodayHoliday:       "statusTodayHoliday",
+	StatusLongWeekend:        "statusLongWeekend",
+	StatusHolidaysOfMonth:    "statusHolidaysOfMonth",
+	MessageSet:               "messageSet",
+	MessageReset:             "messageReset",
+	UnknownMessageKey:        "unknownMessageKey",
+	InvalidTemplate:          "invalidTemplate",
+	HolidayInfo:              "holidayInfo",
+	UnknownHoliday:           "unknownHoliday",
+	FailedToSaveSettings:     "failedToSaveSettings",
 }
 
 var Messages map[string]string
 
 var defaultMessages = map[string]string{
 	MessageKeys.NextHoliday:              "The next holiday is **{{ .HolidayName }}**",
-	MessageKeys.DaysLeft:                 "There are **{{ .Days }}** days left for **{{ .HolidayName }}**",
+	MessageKeys.DaysLeft:                 "There are **{{ .DaysLeft }}** days left for **{{ .HolidayName }}**",
 	MessageKeys.HolidaysOfMonth:          "There are **{{ .Count }}** holidays in **{{ .Month }}**: {{ range .HolidaysList }}**{{ .Name }}**, {{ end }}",
 	MessageKeys.NextLargeHoliday:         "The next large holiday is **{{ .HolidayName }}**",
 	MessageKeys.FailedToParseHolidayDate: "Failed to retrieve the next holiday. Please try again later.",
 	// For no holidays in month message maybe I can pass month and year in a new type
-	MessageKeys.NoHolidaysOfMonth: "There are no holidays in **{{ .Month }}**",
-	MessageKeys.ActivityStatus:    "Waiting {{ .DaysLeft }} days **",
+	MessageKeys.NoHolidaysOfMonth:     "There are no holidays in **{{ .Month }}**",
+	MessageKeys.ActivityStatus:        "Waiting {{ .DaysLeft }} days **",
+	MessageKeys.LongWeekends:          "There are **{{ .Count }}** long weekends in **{{ .Year }}**: {{ range .LongWeekends }}**{{ .Start.Date }}** to **{{ .End.Date }}** ({{ .Length }} days), {{ end }}",
+	MessageKeys.NoLongWeekends:        "There are no long weekends of at least **{{ .MinDays }}** days in **{{ .Year }}**",
+	MessageKeys.UnknownRegion:         "Unknown region, use `/region list` to see the available ones.",
+	MessageKeys.RegionSet:             "Region set to **{{ .Region }}**{{ if .Guild }} for this server{{ end }}",
+	MessageKeys.RegionCleared:         "Region cleared{{ if .Guild }} for this server{{ end }}, only national holidays will be used",
+	MessageKeys.RegionShow:            "Your region: **{{ or .UserRegion \"-\" }}**, server region: **{{ or .GuildRegion \"-\" }}**",
+	MessageKeys.RegionList:            "Available regions: {{ range .Regions }}`{{ .Code }}` ({{ .Name }}), {{ end }}",
+	MessageKeys.MissingPermissions:    "You need the Manage Server permission to do that.",
+	MessageKeys.InvalidDate:           "Invalid date **{{ .Date }}**, use yyyy-mm-dd or mm-dd",
+	MessageKeys.CustomHolidayAdded:    "Added **{{ .Name }}** on **{{ .Date }}** as a day off",
+	MessageKeys.CustomHolidayRemoved:  "Removed the day off on **{{ .Date }}**",
+	MessageKeys.CustomHolidayNotFound: "There is no day off on **{{ .Date }}**",
+	MessageKeys.CustomHolidayList:     "Days off of this server: {{ range .CustomHolidays }}**{{ .Name }}** ({{ .Date }}), {{ end }}",
+	MessageKeys.NoCustomHolidays:      "This server has no days off",
+	MessageKeys.UnknownCountry:        "Unknown country, use `/country list` to see the available ones.",
+	MessageKeys.CountrySet:            "Country set to **{{ .Country }}**{{ if .Guild }} for this server{{ end }}",
+	MessageKeys.CountryCleared:        "Country cleared{{ if .Guild }} for this server{{ end }}, the default country will be used",
+	MessageKeys.CountryShow:           "Your country: **{{ or .UserCountry \"-\" }}**, server country: **{{ or .GuildCountry \"-\" }}**, default: **{{ .DefaultCountry }}**",
+	MessageKeys.CountryList:           "Available countries: {{ range .Countries }}`{{ . }}`, {{ end }}",
+	MessageKeys.APITimeout:            "The holidays service took too long to answer. Please try again later.",
+	MessageKeys.APIUnavailable:        "The holidays service is not available right now. Please try again later.",
+	MessageKeys.HolidaysNotFound:      "There are no holidays published for that year yet.",
+	MessageKeys.IsToday:               "Today is **{{ .HolidayName }}**! 🎉{{ if .Description }}\n{{ .Description }}{{ end }}",
+	MessageKeys.NoLargeHolidays:       "No upcoming large holidays found.",
+	MessageKeys.PrivacySet:            "Responses in this server are now {{ if .Private }}private{{ else }}public{{ end }} by default",
+	MessageKeys.PrivacyShow:           "Responses in this server are {{ if .Private }}private{{ else }}public{{ end }} by default",
+	MessageKeys.ReminderSet:           "Reminder **#{{ .ID }}** set: {{ .DaysBefore }} days before {{ if eq .Kind \"holiday\" }}the holiday on **{{ .Date }}**{{ else if eq .Kind \"long-weekend\" }}every long weekend of {{ .MinDays }} days or more{{ else }}every next holiday{{ end }}{{ if .ChannelID }}, in this channel{{ else }}, by DM{{ end }}",
+	MessageKeys.ReminderList:          "Your reminders: {{ range .Reminders }}**#{{ .ID }}** {{ .Kind }}{{ if .Date }} {{ .Date }}{{ end }} ({{ .DaysBefore }} days before), {{ end }}",
+	MessageKeys.NoReminders:           "You have no reminders",
+	MessageKeys.ReminderCancelled:     "Reminder **#{{ .ID }}** cancelled",
+	MessageKeys.ReminderNotFound:      "You have no reminder **#{{ .ID }}**",
+	MessageKeys.NotAHoliday:           "**{{ .Date }}** is not an upcoming holiday, use yyyy-mm-dd",
+	MessageKeys.Reminder:              "{{ if .Mention }}{{ .Mention }} {{ end }}{{ if eq .DaysLeft 0 }}**{{ .HolidayName }}** is today!{{ else }}**{{ .HolidayName }}** is in **{{ .DaysLeft }}** days ({{ .FullDate }}){{ end }}{{ if .Length }}, a long weekend of {{ .Length }} days{{ end }}",
+	MessageKeys.StatusTodayHoliday:    "{{ if .TodayHoliday }}Today is {{ .TodayHoliday }}{{ end }}",
+	MessageKeys.StatusLongWeekend:     "{{ with .LongWeekend }}Next long weekend in {{ .DaysLeft }} days, {{ .Length }} days off{{ end }}",
+	MessageKeys.StatusHolidaysOfMonth: "{{ with .HolidaysList }}{{ len . }} holidays in {{ $.Month }}{{ end }}",
+	MessageKeys.MessageSet:            "Template **{{ .Key }}** saved for this server, preview:\n{{ .Preview }}",
+	MessageKeys.MessageReset:          "{{ if .Key }}Template **{{ .Key }}** reset to the default{{ else }}Every template reset to the default{{ end }}",
+	MessageKeys.UnknownMessageKey:     "**{{ .Key }}** is not a message key",
+	MessageKeys.InvalidTemplate:       "The template of **{{ .Key }}** is invalid: {{ .Error }}",
+	MessageKeys.HolidayInfo:           "**{{ .HolidayName }}**\n{{ .Description }}\n\n{{ .History }}{{ if .Link }}\nMore: <{{ .Link }}>{{ end }}",
+	MessageKeys.UnknownHoliday:        "I have no information about **{{ .Name }}**",
+	MessageKeys.FailedToSaveSettings:  "Your changes could not be saved. Please try again later.",
 }
 
+// ParseMessagesFromFile returns the messages of a yaml file, nil when it cannot be loaded
 func ParseMessagesFromFile(filename string) map[string]string {
-	var messages map[string]string
-	yamlFile, err := os.Open(filename)
-	if err != nil {
+	if filename == "" {
+		return nil
+	}
+	// parse errors are logged once by loadCatalog
+	messages, err := loadCatalog(filename)
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
 		logrus.Infof("Error opening file %s: %s", filename, err)
-		return defaultMessages
 	}
-	defer yamlFile.Close()
+	return messages
+}
 
-	byteValue, _ := io.ReadAll(yamlFile)
-	err = yaml.Unmarshal(byteValue, &messages)
-	if err != nil {
-		return defaultMessages
+// CheckFile reports whether the messages file can be loaded, nil when no file is configured
+func CheckFile() error {
+	path := config.GetMessagesPath()
+	if path == "" {
+		return nil
 	}
 
+	_, err := loadCatalog(path)
+	return err
+}
+
+// catalogMessages returns the messages of a locale shipped in the messages
+// directory, nil when there is no catalog for it
+func catalogMessages(locale string) map[string]string {
+	messages, _ := loadCatalog(filepath.Join(config.GetMessagesDir(), locale+".yaml"))
 	return messages
 }
 
+// GetMessage returns the global template of key, without guild overrides
 func GetMessage(key string) string {
+	return Lookup(Scope{}, key)
+}
 
-	logrus.Infof("Loading message %s", key)
+// globalMessage returns the template of key in the messages file, the catalog
+// of the configured locale or the built-in defaults
+func globalMessage(key string) string {
 	var fileMessages = ParseMessagesFromFile(config.GetMessagesPath())
 
 	if fileMessages[key] != "" {
 		return fileMessages[key]
 	}
 
+	if message := catalogMessages(config.GetLocale())[key]; message != "" {
+		return message
+	}
+
 	return defaultMessages[key]
 }
 
 func TemplateMessage(message string, data interface{}) string {
-
-	funcMap := template.FuncMap{
-		"sub":        func(a, b int) int { return a - b },
-		"formatDate": helpers.FormatDate,
-	}
-
 	tmpl, err := template.New("message").Funcs(funcMap).Parse(message)
 	if err != nil {
 		logrus.Errorf("Failed to parse message: %v", err)
+		metrics.TemplateFailures.Inc()
 		return MessageKeys.FailedToParseHolidayDate
 	}
 
 	var buf bytes.Buffer
 	if err := tmpl.Execute(&buf, data); err != nil {
 		logrus.Errorf("Failed to execute template: %v", err)
+		metrics.TemplateFailures.Inc()
 		return MessageKeys.FailedToParseHolidayDate
 	}
 
@@ -0,0 +1,109 @@
+package messages
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/FGasquez/alum-bot/internal/config"
+	"github.com/FGasquez/alum-bot/internal/settings"
+	"github.com/bwmarrin/discordgo"
+	"github.com/sirupsen/logrus"
+)
+
+type scopeKey struct{}
+
+// Scope selects the templates of a guild and the catalog of a locale
+type Scope struct {
+	GuildID string
+	// UserID the messages are for, it only shows in the message context
+	UserID string
+	// Locale of the user, e.g. es-ES, its catalog takes precedence over the messages file
+	Locale string
+}
+
+// ScopeOf returns the scope of the guild, user and locale of an interaction
+func ScopeOf(i *discordgo.Interaction) Scope {
+	scope := Scope{GuildID: i.GuildID, Locale: string(i.Locale)}
+	if i.Member != nil && i.Member.User != nil {
+		scope.UserID = i.Member.User.ID
+	} else if i.User != nil {
+		scope.UserID = i.User.ID
+	}
+	return scope
+}
+
+// WithScope returns a copy of ctx carrying the scope of its messages
+func WithScope(ctx context.Context, scope Scope) context.Context {
+	return context.WithValue(ctx, scopeKey{}, scope)
+}
+
+// ScopeFromContext returns the scope carried by ctx, the global one when there is none
+func ScopeFromContext(ctx context.Context) Scope {
+	scope, _ := ctx.Value(scopeKey{}).(Scope)
+	return scope
+}
+
+// Lookup returns the template of key, taken from the first layer defining it:
+// the guild override, the catalog of the user locale, the messages file, the
+// catalog of the configured locale and the built-in defaults.
+func Lookup(scope Scope, key string) string {
+	logrus.Debugf("Loading message %s", key)
+
+	if scope.GuildID != "" {
+		if message := settings.GetGuild(scope.GuildID).Messages[key]; message != "" {
+			return message
+		}
+	}
+
+	if locale := scope.Locale; locale != "" && !sameLanguage(locale, config.GetLocale()) {
+		for _, candidate := range localeCandidates(locale) {
+			if message := catalogMessages(candidate)[key]; message != "" {
+				return message
+			}
+		}
+	}
+
+	return globalMessage(key)
+}
+
+// Get returns the template of key in the scope carried by ctx
+func Get(ctx context.Context, key string) string {
+	return Lookup(ScopeFromContext(ctx), key)
+}
+
+// Render returns the template of key in the scope carried by ctx rendered with values
+func Render(ctx context.Context, key string, values Context) string {
+	return TemplateMessage(Get(ctx, key), values)
+}
+
+// Keys returns every message key, sorted
+func Keys() []string {
+	keys := make([]string, 0, len(defaultMessages))
+	for key := range defaultMessages {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// IsKey reports whether key is a known message key
+func IsKey(key string) bool {
+	_, ok := defaultMessages[key]
+	return ok
+}
+
+// localeCandidates returns the catalogs tried for a locale, es-ES before es
+func localeCandidates(locale string) []string {
+	if language, _, found := strings.Cut(locale, "-"); found {
+		return []string{locale, language}
+	}
+	return []string{locale}
+}
+
+// sameLanguage reports whether two locales share their language, e.g. es-ES and es
+func sameLanguage(a, b string) bool {
+	a, _, _ = strings.Cut(a, "-")
+	b, _, _ = strings.Cut(b, "-")
+	return strings.EqualFold(a, b)
+}
@@ -0,0 +1,147 @@
+package messages
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"text/template"
+	"time"
+
+	"github.com/FGasquez/alum-bot/internal/config"
+	"github.com/FGasquez/alum-bot/internal/types"
+)
+
+// Validate checks a template of key: it must parse, reference only fields
+// of Context, and render the sample context
+func Validate(key string, message string) error {
+	if !IsKey(key) {
+		return fmt.Errorf("unknown message key %q", key)
+	}
+
+	tmpl, err := template.New(key).Funcs(funcMap).Parse(message)
+	if err != nil {
+		return err
+	}
+	if err := checkFields(tmpl); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	return tmpl.Execute(&buf, Sample())
+}
+
+// ValidateAll validates the built-in messages, the catalogs in the messages
+// directory and the messages file, reporting every invalid template
+func ValidateAll() error {
+	var errs []error
+	check := func(source string, messages map[string]string) {
+		keys := make([]string, 0, len(messages))
+		for key := range messages {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if err := Validate(key, messages[key]); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %s: %w", source, key, err))
+			}
+		}
+	}
+
+	check("built-in messages", defaultMessages)
+
+	catalogs, _ := filepath.Glob(filepath.Join(config.GetMessagesDir(), "*.yaml"))
+	for _, path := range catalogs {
+		messages, err := loadCatalog(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		check(path, messages)
+	}
+
+	if path := config.GetMessagesPath(); path != "" {
+		messages, err := loadCatalog(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		} else {
+			check(path, messages)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid message templates:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Sample returns a context with every field set, to validate and preview templates
+func Sample() Context {
+	holiday := types.ParsedHolidays{
+		Date:              "2024-06-20",
+		Type:              "inamovible",
+		Name:              "Paso a la Inmortalidad del General Manuel Belgrano",
+		FormattedDate:     "Jueves 20 de Junio",
+		NamedDate:         types.NamedDate{Day: "Jueves", Month: "Junio"},
+		RawDate:           types.RawDate{Day: 20, Month: 6, Year: 2024},
+		FullDate:          "2024-06-20",
+		DaysLeftToHoliday: 3,
+	}
+	bridge := holiday
+	bridge.Date, bridge.FullDate, bridge.Type, bridge.Name = "2024-06-21", "2024-06-21", types.Bridge, "Puente turístico"
+	holiday.Adjacent = []types.ParsedHolidays{bridge}
+	longWeekend := types.LongWeekend{
+		Start:      holiday,
+		End:        bridge,
+		Length:     4,
+		Holidays:   []types.ParsedHolidays{holiday},
+		BridgeDays: []types.ParsedHolidays{bridge},
+		Days:       []types.ParsedHolidays{holiday, bridge},
+		DaysLeft:   3,
+	}
+	reminder := Reminder{
+		ID:         "1",
+		Kind:       "holiday",
+		DaysBefore: 1,
+		Date:       holiday.Date,
+		ChannelID:  "channel",
+		Country:    "AR",
+	}
+
+	c := Context{
+		GuildID: "guild",
+		UserID:  "user",
+		Locale:  "es-ES",
+		Now:     time.Date(2024, 6, 17, 12, 0, 0, 0, time.Local),
+	}
+	for _, option := range []ContextOption{
+		Holiday(holiday, 3),
+		LongWeekend(&longWeekend),
+		Month("Junio", []types.ParsedHolidays{holiday, bridge}),
+		LongWeekends(2024, 3, []types.LongWeekend{longWeekend}),
+	} {
+		option(&c)
+	}
+
+	c.Description = "Aniversario del paso a la inmortalidad del creador de la bandera."
+	c.History = "Manuel Belgrano murió el 20 de junio de 1820 en Buenos Aires."
+	c.Link = "https://es.wikipedia.org/wiki/Manuel_Belgrano"
+	c.TodayHoliday = holiday.Name
+	c.Guild = true
+	c.Country, c.UserCountry, c.GuildCountry, c.DefaultCountry = "AR", "AR", "UY", "AR"
+	c.Countries = []string{"AR", "UY"}
+	c.Region, c.UserRegion, c.GuildRegion = "Córdoba", "cba", "caba"
+	c.Regions = []Region{{Code: "cba", Name: "Córdoba", Country: "AR"}}
+	c.Private = true
+	c.Name = "Aniversario"
+	c.CustomHolidays = []CustomHoliday{{Date: "05-13", Name: "Aniversario"}}
+	c.ID, c.Kind, c.DaysBefore, c.Date, c.ChannelID = reminder.ID, reminder.Kind, reminder.DaysBefore, reminder.Date, reminder.ChannelID
+	c.Reminders = []Reminder{reminder}
+	c.Mention = "<@user>"
+	c.Key = MessageKeys.NextHoliday
+	c.Preview = "The next holiday is **" + holiday.Name + "**"
+	c.Error = `function "foo" not defined`
+	return c
+}
@@ -0,0 +1,45 @@
+package messages
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// TestValidateAll checks the built-in messages and the shipped catalogs
+func TestValidateAll(t *testing.T) {
+	viper.Set("messages-dir", "../../messages")
+	defer viper.Set("messages-dir", nil)
+
+	if err := ValidateAll(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		valid   bool
+	}{
+		{name: "holiday field", message: "{{ .HolidayName }} {{ .Description }}", valid: true},
+		{name: "field of a list", message: "{{ range .Reminders }}{{ .ID }} {{ .Kind }}{{ end }}", valid: true},
+		{name: "field of a group", message: "{{ .SettingsData.Country }}", valid: true},
+		{name: "unknown field", message: "{{ .Nope }}", valid: false},
+		{name: "unknown field in a branch", message: "{{ if .IsToday }}{{ .Nope }}{{ end }}", valid: false},
+		{name: "unknown field of a list", message: "{{ range .Regions }}{{ .Nope }}{{ end }}", valid: false},
+		{name: "syntax error", message: "{{ .HolidayName ", valid: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := Validate(MessageKeys.NextHoliday, test.message)
+			if test.valid && err != nil {
+				t.Errorf("got %v, want no error", err)
+			}
+			if !test.valid && err == nil {
+				t.Error("got no error")
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "alumbot"
+
+// Cache lookup results
+const (
+	CacheMemoryHit = "memory_hit"
+	CacheDiskHit   = "disk_hit"
+	CacheMiss      = "miss"
+	CacheStale     = "stale"
+)
+
+var (
+	CommandInvocations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "command_invocations_total",
+		Help:      "Slash command invocations by command name.",
+	}, []string{"command"})
+
+	CommandDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "command_duration_seconds",
+		Help:      "Time to answer a slash command by command name.",
+		Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"command"})
+
+	CommandErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "command_errors_total",
+		Help:      "Slash commands answered with an error, or that panicked, by command name.",
+	}, []string{"command"})
+
+	HolidayAPIRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "holiday_api_requests_total",
+		Help:      "Requests to the holiday API by HTTP status, or error kind when there was no response.",
+	}, []string{"status"})
+
+	HolidayAPIDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "holiday_api_request_duration_seconds",
+		Help:      "Duration of the requests to the holiday API.",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	CacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_lookups_total",
+		Help:      "Holiday cache lookups by result: memory_hit, disk_hit, miss or stale.",
+	}, []string{"result"})
+
+	TemplateFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "template_render_failures_total",
+		Help:      "Message templates that failed to parse or execute.",
+	})
+
+	GatewayConnected = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "gateway_connected",
+		Help:      "Whether the Discord gateway connection is up (1) or down (0).",
+	})
+)
+
+// Handler serves the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,165 @@
+package regions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FGasquez/alum-bot/internal/config"
+	"github.com/FGasquez/alum-bot/internal/helpers"
+	"github.com/FGasquez/alum-bot/internal/types"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+// regions written before multi-country support belong to Argentina
+const defaultCountry = "AR"
+
+// RegionHoliday is a holiday defined in a region file. Date is either a full
+// date (yyyy-mm-dd), applying only to that year, or a month and day (mm-dd)
+// repeating every year.
+type RegionHoliday struct {
+	Date string `yaml:"date"`
+	Name string `yaml:"name"`
+	Type string `yaml:"type"`
+}
+
+// Region is a set of holidays layered on top of the national ones of its country
+type Region struct {
+	Code     string          `yaml:"-"`
+	Name     string          `yaml:"name"`
+	Country  string          `yaml:"country"`
+	Holidays []RegionHoliday `yaml:"holidays"`
+}
+
+// HolidaysFor returns the holidays of the region for the given year
+func (r Region) HolidaysFor(year int) []types.Holiday {
+	var holidays []types.Holiday
+	for _, h := range r.Holidays {
+		holidayType := h.Type
+		if holidayType == "" {
+			holidayType = types.Provincial
+		}
+
+		if !helpers.ValidHolidayDate(h.Date) {
+			logrus.Warnf("Invalid date %q in region %s", h.Date, r.Code)
+			continue
+		}
+		date, ok := helpers.DateForYear(h.Date, year)
+		if !ok {
+			continue
+		}
+
+		holidays = append(holidays, types.Holiday{
+			Date: date,
+			Type: holidayType,
+			Name: h.Name,
+		})
+	}
+
+	return holidays
+}
+
+type cachedRegion struct {
+	region  Region
+	modTime time.Time
+}
+
+var (
+	mu    sync.Mutex
+	cache = map[string]cachedRegion{}
+	// generation counts the region files parsed, see Generation
+	generation uint64
+)
+
+// Get loads the region with the given code from the regions directory. The
+// parsed regions are kept until their file changes.
+func Get(code string) (Region, error) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if code == "" || strings.ContainsAny(code, `/\.`) {
+		return Region{}, fmt.Errorf("unknown region %q", code)
+	}
+
+	path := filepath.Join(config.GetRegionsDir(), code+".yaml")
+	info, err := os.Stat(path)
+	if err != nil {
+		return Region{}, fmt.Errorf("unknown region %q: %w", code, err)
+	}
+
+	mu.Lock()
+	cached, ok := cache[path]
+	mu.Unlock()
+	if ok && cached.modTime.Equal(info.ModTime()) {
+		return cached.region, nil
+	}
+
+	region, err := parse(path, code)
+	if err != nil {
+		return Region{}, err
+	}
+
+	mu.Lock()
+	generation++
+	cache[path] = cachedRegion{region: region, modTime: info.ModTime()}
+	mu.Unlock()
+
+	return region, nil
+}
+
+// Generation returns a number that changes whenever a region file is parsed again
+func Generation() uint64 {
+	mu.Lock()
+	defer mu.Unlock()
+	return generation
+}
+
+func parse(path string, code string) (Region, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Region{}, fmt.Errorf("unknown region %q: %w", code, err)
+	}
+
+	var region Region
+	if err := yaml.Unmarshal(data, &region); err != nil {
+		return Region{}, fmt.Errorf("failed to parse region %q: %w", code, err)
+	}
+	region.Code = code
+	if region.Name == "" {
+		region.Name = code
+	}
+	if region.Country == "" {
+		region.Country = defaultCountry
+	}
+	region.Country = strings.ToUpper(region.Country)
+
+	return region, nil
+}
+
+// List returns all the regions available in the regions directory, sorted by name
+func List() []Region {
+	files, err := filepath.Glob(filepath.Join(config.GetRegionsDir(), "*.yaml"))
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to list regions")
+		return nil
+	}
+
+	var regions []Region
+	for _, file := range files {
+		region, err := Get(strings.TrimSuffix(filepath.Base(file), ".yaml"))
+		if err != nil {
+			logrus.WithError(err).Warn("Failed to load region")
+			continue
+		}
+		regions = append(regions, region)
+	}
+
+	sort.Slice(regions, func(i, j int) bool {
+		return regions[i].Name < regions[j].Name
+	})
+
+	return regions
+}
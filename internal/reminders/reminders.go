@@ -0,0 +1,156 @@
+package reminders
+
+import (
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/FGasquez/alum-bot/internal/config"
+	"github.com/FGasquez/alum-bot/internal/jsonstore"
+)
+
+const remindersFileName = "reminders.json"
+
+// sentHistory is how many sent occurrences are kept for each reminder
+const sentHistory = 10
+
+type Kind string
+
+const (
+	// NextHoliday reminds before every next holiday
+	NextHoliday Kind = "next"
+	// Holiday reminds once before a specific holiday
+	Holiday Kind = "holiday"
+	// LongWeekend reminds before every long weekend
+	LongWeekend Kind = "long-weekend"
+)
+
+// Reminder is a subscription of a user to be told some days before a holiday.
+// It is delivered by DM, or mentioning the user in ChannelID when set.
+type Reminder struct {
+	ID         string `json:"id"`
+	UserID     string `json:"userId"`
+	GuildID    string `json:"guildId,omitempty"`
+	ChannelID  string `json:"channelId,omitempty"`
+	Kind       Kind   `json:"kind"`
+	DaysBefore int    `json:"daysBefore"`
+	// Date of the holiday for Holiday reminders, yyyy-mm-dd
+	Date string `json:"date,omitempty"`
+	// MinDays is the minimum length of the long weekends for LongWeekend reminders
+	MinDays int    `json:"minDays,omitempty"`
+	Country string `json:"country,omitempty"`
+	Region  string `json:"region,omitempty"`
+	// Sent holds the dates of the holidays already reminded, newest last
+	Sent      []string  `json:"sent,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type store struct {
+	NextID    int                 `json:"nextId"`
+	Reminders map[string]Reminder `json:"reminders"`
+}
+
+var remindersStore = jsonstore.New(remindersPath, func(s *store) {
+	if s.Reminders == nil {
+		s.Reminders = map[string]Reminder{}
+	}
+})
+
+func remindersPath() string {
+	return filepath.Join(config.GetDataDir(), remindersFileName)
+}
+
+// Reset forgets the loaded reminders, so they are read again from the data directory
+func Reset() {
+	remindersStore.Reset()
+}
+
+// Add stores a new reminder and returns it with its ID
+func Add(reminder Reminder) (Reminder, error) {
+	err := remindersStore.Update(func(s *store) bool {
+		s.NextID++
+		reminder.ID = strconv.Itoa(s.NextID)
+		if reminder.CreatedAt.IsZero() {
+			reminder.CreatedAt = time.Now()
+		}
+		s.Reminders[reminder.ID] = reminder
+		return true
+	})
+	return reminder, err
+}
+
+// List returns the reminders of a user sorted by ID, every reminder for an
+// empty user. They are read again from disk when another instance changed them.
+func List(userID string) []Reminder {
+	var list []Reminder
+	remindersStore.Read(func(s *store) {
+		for _, reminder := range s.Reminders {
+			if userID == "" || reminder.UserID == userID {
+				list = append(list, reminder)
+			}
+		}
+	})
+	sort.Slice(list, func(a, b int) bool {
+		idA, _ := strconv.Atoi(list[a].ID)
+		idB, _ := strconv.Atoi(list[b].ID)
+		return idA < idB
+	})
+	return list
+}
+
+// Cancel removes a reminder of a user, reporting whether it existed
+func Cancel(userID, id string) (bool, error) {
+	var cancelled bool
+	err := remindersStore.Update(func(s *store) bool {
+		reminder, ok := s.Reminders[id]
+		if !ok || reminder.UserID != userID {
+			return false
+		}
+		delete(s.Reminders, id)
+		cancelled = true
+		return true
+	})
+	return cancelled, err
+}
+
+// Delete removes a reminder that will not be sent anymore
+func Delete(id string) error {
+	return remindersStore.Update(func(s *store) bool {
+		if _, ok := s.Reminders[id]; !ok {
+			return false
+		}
+		delete(s.Reminders, id)
+		return true
+	})
+}
+
+// MarkSent records that the reminder of the holiday on date is being sent.
+// It reports false when it was already recorded, or the reminder is gone, so
+// a reminder is never sent twice, not even across restarts or instances.
+func MarkSent(id, date string) (bool, error) {
+	var marked bool
+	err := remindersStore.Update(func(s *store) bool {
+		reminder, ok := s.Reminders[id]
+		if !ok {
+			return false
+		}
+		for _, sent := range reminder.Sent {
+			if sent == date {
+				return false
+			}
+		}
+
+		reminder.Sent = append(reminder.Sent, date)
+		if len(reminder.Sent) > sentHistory {
+			reminder.Sent = reminder.Sent[len(reminder.Sent)-sentHistory:]
+		}
+		s.Reminders[id] = reminder
+		marked = true
+		return true
+	})
+	if err != nil {
+		return false, err
+	}
+	return marked, nil
+}
@@ -0,0 +1,88 @@
+package reminders
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func useDataDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	viper.Set("data-dir", dir)
+	Reset()
+	t.Cleanup(func() {
+		viper.Set("data-dir", nil)
+		Reset()
+	})
+	return dir
+}
+
+// TestReloadsChangesOfOtherInstances replaces the file like another instance
+// sharing the data directory does, and checks it is neither missed nor overwritten
+func TestReloadsChangesOfOtherInstances(t *testing.T) {
+	dir := useDataDir(t)
+	if _, err := Add(Reminder{UserID: "a", Kind: NextHoliday}); err != nil {
+		t.Fatal(err)
+	}
+
+	other := `{"nextId": 2, "reminders": {
+		"1": {"id": "1", "userId": "a", "kind": "next"},
+		"2": {"id": "2", "userId": "b", "kind": "next"}}}`
+	if err := os.WriteFile(filepath.Join(dir, remindersFileName), []byte(other), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(List("")); got != 2 {
+		t.Fatalf("%d reminders listed, want the 2 on disk", got)
+	}
+
+	added, err := Add(Reminder{UserID: "c", Kind: NextHoliday})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added.ID != "3" {
+		t.Errorf("added reminder %s, want 3", added.ID)
+	}
+
+	Reset()
+	if got := len(List("")); got != 3 {
+		t.Errorf("%d reminders saved, want 3", got)
+	}
+}
+
+func TestMarkSentOnce(t *testing.T) {
+	useDataDir(t)
+	reminder, err := Add(Reminder{UserID: "a", Kind: NextHoliday})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	marked := make(chan bool, 10)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := MarkSent(reminder.ID, "2025-07-09")
+			if err != nil {
+				t.Error(err)
+			}
+			marked <- ok
+		}()
+	}
+	wg.Wait()
+	close(marked)
+
+	count := 0
+	for ok := range marked {
+		if ok {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("marked sent %d times, want 1", count)
+	}
+}
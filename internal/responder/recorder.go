@@ -0,0 +1,119 @@
+package responder
+
+import (
+	"context"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Response is a message sent through a Recorder
+type Response struct {
+	Content   string
+	Embed     *discordgo.MessageEmbed
+	Ephemeral bool
+	Followup  bool
+	// Key is the message key of error replies
+	Key string
+	// Modal is the modal shown to the user
+	Modal *discordgo.InteractionResponseData
+}
+
+// Recorder is a Responder that keeps the responses instead of sending them,
+// to run handlers without a discord session
+type Recorder struct {
+	// ctx carries the messages scope of the interaction, as in Interaction
+	ctx       context.Context
+	mu        sync.Mutex
+	deferred  bool
+	ephemeral bool
+	responses []Response
+}
+
+// NewRecorder returns a Recorder of the responses to i, rendering the error
+// replies in its scope as Interaction does
+func NewRecorder(ctx context.Context, i *discordgo.InteractionCreate) *Recorder {
+	return &Recorder{ctx: scoped(ctx, i)}
+}
+
+func (r *Recorder) SetEphemeral(ephemeral bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ephemeral = ephemeral
+}
+
+func (r *Recorder) Defer() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deferred = true
+	return nil
+}
+
+func (r *Recorder) Deferred() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.deferred
+}
+
+func (r *Recorder) Reply(content string) error {
+	return r.record(Response{Content: content})
+}
+
+func (r *Recorder) ReplyEmbed(embed *discordgo.MessageEmbed) error {
+	return r.record(Response{Embed: embed})
+}
+
+func (r *Recorder) ReplyEphemeral(content string) error {
+	return r.record(Response{Content: content, Ephemeral: true})
+}
+
+func (r *Recorder) ReplyError(key string) error {
+	return r.record(Response{
+		Content: errorMessage(r.ctx, key),
+		Key:     key,
+	})
+}
+
+func (r *Recorder) Failed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, response := range r.responses {
+		if response.Key != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Recorder) Modal(data *discordgo.InteractionResponseData) error {
+	return r.record(Response{Modal: data})
+}
+
+func (r *Recorder) Followup(content string) error {
+	return r.record(Response{Content: content, Followup: true})
+}
+
+// Responses returns the responses recorded so far
+func (r *Recorder) Responses() []Response {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Response(nil), r.responses...)
+}
+
+// Last returns the last recorded response
+func (r *Recorder) Last() (Response, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.responses) == 0 {
+		return Response{}, false
+	}
+	return r.responses[len(r.responses)-1], true
+}
+
+func (r *Recorder) record(response Response) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	response.Ephemeral = response.Ephemeral || r.ephemeral
+	r.responses = append(r.responses, response)
+	return nil
+}
@@ -0,0 +1,37 @@
+package responder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/FGasquez/alum-bot/internal/messages"
+	"github.com/FGasquez/alum-bot/internal/settings"
+	"github.com/bwmarrin/discordgo"
+	"github.com/spf13/viper"
+)
+
+func TestRecorderReplyErrorUsesGuildTemplate(t *testing.T) {
+	viper.Set("data-dir", t.TempDir())
+	settings.Reset()
+	defer settings.Reset()
+
+	key := messages.MessageKeys.FailedToParseHolidayDate
+	err := settings.UpdateGuild("guild", func(s *settings.Settings) {
+		s.Messages = map[string]string{key: "server error for {{ .GuildID }}"}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	i := &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{GuildID: "guild"}}
+	recorder := NewRecorder(context.Background(), i)
+	recorder.ReplyError(key)
+
+	last, _ := recorder.Last()
+	if want := "server error for guild"; last.Content != want {
+		t.Errorf("ReplyError rendered %q, want %q", last.Content, want)
+	}
+	if !recorder.Failed() {
+		t.Error("Failed is false after an error reply")
+	}
+}
@@ -0,0 +1,195 @@
+package responder
+
+import (
+	"context"
+
+	"github.com/FGasquez/alum-bot/internal/logging"
+	"github.com/FGasquez/alum-bot/internal/messages"
+	"github.com/bwmarrin/discordgo"
+)
+
+// Responder answers an interaction. Once deferred, replies edit the deferred
+// response instead of creating a new one, so handlers do not need to know
+// whether the response was deferred.
+type Responder interface {
+	// SetEphemeral makes the following replies, and the deferred response,
+	// visible only to the user of the interaction
+	SetEphemeral(ephemeral bool)
+	// Defer acknowledges the interaction and shows a thinking state
+	Defer() error
+	// Deferred reports whether the response was deferred
+	Deferred() bool
+	// Reply sends a public message
+	Reply(content string) error
+	// ReplyEmbed sends a public message with an embed
+	ReplyEmbed(embed *discordgo.MessageEmbed) error
+	// ReplyEphemeral sends a message only the user of the interaction can see
+	ReplyEphemeral(content string) error
+	// ReplyError sends the message of the given key
+	ReplyError(key string) error
+	// Modal answers the interaction with a modal, it cannot be deferred
+	Modal(data *discordgo.InteractionResponseData) error
+	// Followup sends another message after the interaction was answered
+	Followup(content string) error
+	// Failed reports whether an error reply was sent
+	Failed() bool
+}
+
+// Session is the part of a discord session used to answer interactions
+type Session interface {
+	InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, options ...discordgo.RequestOption) error
+	InteractionResponseEdit(interaction *discordgo.Interaction, newresp *discordgo.WebhookEdit, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	InteractionResponseDelete(interaction *discordgo.Interaction, options ...discordgo.RequestOption) error
+	FollowupMessageCreate(interaction *discordgo.Interaction, wait bool, data *discordgo.WebhookParams, options ...discordgo.RequestOption) (*discordgo.Message, error)
+}
+
+// Interaction is the Responder that answers through a discord session
+type Interaction struct {
+	// ctx carries the logger and the messages scope of the interaction
+	ctx         context.Context
+	session     Session
+	interaction *discordgo.Interaction
+	deferred    bool
+	ephemeral   bool
+	failed      bool
+}
+
+// New returns a Responder answering i through s, logging with the logger of ctx
+func New(ctx context.Context, s Session, i *discordgo.InteractionCreate) Responder {
+	return &Interaction{
+		ctx:         scoped(ctx, i),
+		session:     s,
+		interaction: i.Interaction,
+	}
+}
+
+func (r *Interaction) SetEphemeral(ephemeral bool) {
+	r.ephemeral = ephemeral
+}
+
+// Defer acknowledges the interaction and shows a thinking state, discord
+// requires an answer within 3 seconds and the token lasts 15 minutes after it
+func (r *Interaction) Defer() error {
+	if r.deferred {
+		return nil
+	}
+
+	err := r.session.InteractionRespond(r.interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: r.flags(),
+		},
+	})
+	if err != nil {
+		logging.FromContext(r.ctx).WithError(err).Error("Failed to defer interaction response")
+		return err
+	}
+
+	r.deferred = true
+	return nil
+}
+
+func (r *Interaction) Deferred() bool {
+	return r.deferred
+}
+
+func (r *Interaction) Reply(content string) error {
+	return r.respond(&discordgo.InteractionResponseData{Content: content})
+}
+
+func (r *Interaction) ReplyEmbed(embed *discordgo.MessageEmbed) error {
+	return r.respond(&discordgo.InteractionResponseData{Embeds: []*discordgo.MessageEmbed{embed}})
+}
+
+// ReplyEphemeral sends an ephemeral message. A public deferred response is
+// replaced by an ephemeral follow-up message.
+func (r *Interaction) ReplyEphemeral(content string) error {
+	if !r.deferred || r.ephemeral {
+		return r.respond(&discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+	}
+
+	if err := r.session.InteractionResponseDelete(r.interaction); err != nil {
+		logging.FromContext(r.ctx).WithError(err).Warn("Failed to delete deferred response")
+	}
+	_, err := r.session.FollowupMessageCreate(r.interaction, true, &discordgo.WebhookParams{
+		Content: content,
+		Flags:   discordgo.MessageFlagsEphemeral,
+	})
+	if err != nil {
+		logging.FromContext(r.ctx).WithError(err).Error("Failed to respond interaction")
+	}
+	return err
+}
+
+func (r *Interaction) ReplyError(key string) error {
+	r.failed = true
+	return r.Reply(errorMessage(r.ctx, key))
+}
+
+func (r *Interaction) Modal(data *discordgo.InteractionResponseData) error {
+	err := r.session.InteractionRespond(r.interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: data,
+	})
+	if err != nil {
+		logging.FromContext(r.ctx).WithError(err).Error("Failed to respond modal")
+	}
+	return err
+}
+
+func (r *Interaction) Followup(content string) error {
+	_, err := r.session.FollowupMessageCreate(r.interaction, true, &discordgo.WebhookParams{
+		Content: content,
+		Flags:   r.flags(),
+	})
+	if err != nil {
+		logging.FromContext(r.ctx).WithError(err).Error("Failed to send follow-up message")
+	}
+	return err
+}
+
+func (r *Interaction) Failed() bool {
+	return r.failed
+}
+
+// respond sends the first response, or edits the deferred one
+func (r *Interaction) respond(data *discordgo.InteractionResponseData) error {
+	var err error
+	if r.deferred {
+		_, err = r.session.InteractionResponseEdit(r.interaction, &discordgo.WebhookEdit{
+			Content: &data.Content,
+			Embeds:  &data.Embeds,
+		})
+	} else {
+		data.Flags |= r.flags()
+		err = r.session.InteractionRespond(r.interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: data,
+		})
+	}
+
+	if err != nil {
+		logging.FromContext(r.ctx).WithError(err).Error("Failed to respond interaction")
+	}
+	return err
+}
+
+func (r *Interaction) flags() discordgo.MessageFlags {
+	if r.ephemeral {
+		return discordgo.MessageFlagsEphemeral
+	}
+	return 0
+}
+
+// scoped returns a copy of ctx rendering the messages in the guild and locale of i
+func scoped(ctx context.Context, i *discordgo.InteractionCreate) context.Context {
+	return messages.WithScope(ctx, messages.ScopeOf(i.Interaction))
+}
+
+// errorMessage renders the message of an error reply
+func errorMessage(ctx context.Context, key string) string {
+	return messages.Render(ctx, key, messages.NewContext(ctx))
+}
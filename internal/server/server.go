@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/FGasquez/alum-bot/internal/health"
+	"github.com/FGasquez/alum-bot/internal/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+const shutdownTimeout = 5 * time.Second
+
+// Server is the HTTP server exposing the bot metrics and health checks
+type Server struct {
+	http *http.Server
+}
+
+// New returns the server listening on addr, nil when addr is empty
+func New(addr string) *Server {
+	if addr == "" {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics.Handler())
+	mux.HandleFunc("GET /healthz", health.Healthz)
+	mux.HandleFunc("GET /readyz", health.Readyz)
+
+	return &Server{http: &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}}
+}
+
+// Run serves until ctx is cancelled, then shuts down waiting for the requests
+// in progress. Failing to listen is returned right away.
+func (s *Server) Run(ctx context.Context) error {
+	if s == nil {
+		<-ctx.Done()
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", s.http.Addr)
+	if err != nil {
+		return err
+	}
+
+	served := make(chan error, 1)
+	go func() {
+		logrus.WithField("addr", s.http.Addr).Info("Starting HTTP server")
+		served <- s.http.Serve(listener)
+	}()
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := s.http.Shutdown(shutdownCtx); err != nil {
+		logrus.WithError(err).Warn("Failed to shut down HTTP server")
+	}
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package settings
+
+import (
+	"maps"
+	"path/filepath"
+	"slices"
+
+	"github.com/FGasquez/alum-bot/internal/config"
+	"github.com/FGasquez/alum-bot/internal/jsonstore"
+)
+
+const settingsFileName = "settings.json"
+
+// CustomHoliday is a day off defined by a guild. Date is either a full date
+// (yyyy-mm-dd) or a month and day (mm-dd) repeating every year.
+type CustomHoliday struct {
+	Date string `json:"date"`
+	Name string `json:"name"`
+}
+
+// Settings holds the preferences of a guild or a user
+type Settings struct {
+	Country        string          `json:"country,omitempty"`
+	Region         string          `json:"region,omitempty"`
+	CustomHolidays []CustomHoliday `json:"customHolidays,omitempty"`
+	// Private makes the command responses ephemeral by default, guilds only
+	Private bool `json:"private,omitempty"`
+	// Messages are the templates overriding the global ones, keyed by message key, guilds only
+	Messages map[string]string `json:"messages,omitempty"`
+}
+
+// clone returns a copy of s sharing no slice or map with it
+func (s Settings) clone() Settings {
+	s.CustomHolidays = slices.Clone(s.CustomHolidays)
+	s.Messages = maps.Clone(s.Messages)
+	return s
+}
+
+type store struct {
+	Guilds map[string]Settings `json:"guilds"`
+	Users  map[string]Settings `json:"users"`
+}
+
+var settingsStore = jsonstore.New(settingsPath, func(s *store) {
+	if s.Guilds == nil {
+		s.Guilds = map[string]Settings{}
+	}
+	if s.Users == nil {
+		s.Users = map[string]Settings{}
+	}
+})
+
+func settingsPath() string {
+	return filepath.Join(config.GetDataDir(), settingsFileName)
+}
+
+// GetGuild returns a copy of the settings of the given guild
+func GetGuild(guildID string) Settings {
+	var guild Settings
+	settingsStore.Read(func(s *store) { guild = s.Guilds[guildID].clone() })
+	return guild
+}
+
+// GetUser returns a copy of the settings of the given user
+func GetUser(userID string) Settings {
+	var user Settings
+	settingsStore.Read(func(s *store) { user = s.Users[userID].clone() })
+	return user
+}
+
+// Revision returns a number that changes whenever any setting changes
+func Revision() uint64 {
+	return settingsStore.Revision()
+}
+
+// Reset forgets the loaded settings, so they are read again from the data directory
+func Reset() {
+	settingsStore.Reset()
+}
+
+// UpdateGuild applies update to the settings of the given guild and persists them
+func UpdateGuild(guildID string, update func(*Settings)) error {
+	return settingsStore.Update(func(s *store) bool {
+		guild := s.Guilds[guildID].clone()
+		update(&guild)
+		s.Guilds[guildID] = guild
+		return true
+	})
+}
+
+// UpdateUser applies update to the settings of the given user and persists them
+func UpdateUser(userID string, update func(*Settings)) error {
+	return settingsStore.Update(func(s *store) bool {
+		user := s.Users[userID].clone()
+		update(&user)
+		s.Users[userID] = user
+		return true
+	})
+}
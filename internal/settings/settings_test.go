@@ -0,0 +1,75 @@
+package settings
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func useDataDir(t *testing.T) {
+	t.Helper()
+	viper.Set("data-dir", t.TempDir())
+	Reset()
+	t.Cleanup(func() {
+		viper.Set("data-dir", nil)
+		Reset()
+	})
+}
+
+func TestGetGuildReturnsCopy(t *testing.T) {
+	useDataDir(t)
+	err := UpdateGuild("guild", func(s *Settings) {
+		s.Messages = map[string]string{"nextHoliday": "stored"}
+		s.CustomHolidays = []CustomHoliday{{Date: "05-04", Name: "stored"}}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := GetGuild("guild")
+	got.Messages["nextHoliday"] = "changed"
+	got.CustomHolidays[0].Name = "changed"
+
+	again := GetGuild("guild")
+	if again.Messages["nextHoliday"] != "stored" || again.CustomHolidays[0].Name != "stored" {
+		t.Errorf("changing the returned settings changed the stored ones: %+v", again)
+	}
+}
+
+// TestGetGuildWhileUpdating fails under -race when the readers share the
+// maps changed by the updates
+func TestGetGuildWhileUpdating(t *testing.T) {
+	useDataDir(t)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for n := range 50 {
+			err := UpdateGuild("guild", func(s *Settings) {
+				if s.Messages == nil {
+					s.Messages = map[string]string{}
+				}
+				s.Messages[fmt.Sprint(n)] = "template"
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for range 50 {
+			for key, template := range GetGuild("guild").Messages {
+				_, _ = key, template
+			}
+		}
+	}()
+	wg.Wait()
+
+	if got := len(GetGuild("guild").Messages); got != 50 {
+		t.Errorf("%d templates stored, want 50", got)
+	}
+}
@@ -0,0 +1,27 @@
+package sources
+
+import (
+	"context"
+
+	"github.com/FGasquez/alum-bot/internal/holidayapi"
+	"github.com/FGasquez/alum-bot/internal/types"
+)
+
+// ArgentinaDatos fetches the argentinian holidays from api.argentinadatos.com
+type ArgentinaDatos struct {
+	// Client defaults to a client configured from the api-* settings
+	Client *holidayapi.Client
+}
+
+func (a ArgentinaDatos) Holidays(ctx context.Context, year int) ([]types.Holiday, error) {
+	client := a.Client
+	if client == nil {
+		client = holidayapi.NewClient()
+	}
+
+	return client.Holidays(ctx, year)
+}
+
+func init() {
+	Register("AR", Cached{Country: "AR", Provider: ArgentinaDatos{}})
+}
@@ -0,0 +1,44 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FGasquez/alum-bot/internal/cache"
+	"github.com/FGasquez/alum-bot/internal/types"
+)
+
+// Cached keeps the holidays of a remote provider in memory and on disk
+type Cached struct {
+	Country  string
+	Provider Provider
+	// Cache defaults to the cache configured from the cache-* settings
+	Cache *cache.Cache
+}
+
+func (c Cached) Holidays(ctx context.Context, year int) ([]types.Holiday, error) {
+	return c.cache().Get(ctx, c.key(year), func(ctx context.Context) ([]types.Holiday, error) {
+		return c.Provider.Holidays(ctx, year)
+	})
+}
+
+// IsCached reports whether the holidays of the year can be answered without reaching the provider
+func (c Cached) IsCached(year int) bool {
+	return c.cache().Cached(c.key(year))
+}
+
+// Generation returns the generation of the cached holidays of the year, false when they must be fetched
+func (c Cached) Generation(year int) (uint64, bool) {
+	return c.cache().Generation(c.key(year))
+}
+
+func (c Cached) key(year int) string {
+	return fmt.Sprintf("%s_%d", NormalizeCountry(c.Country), year)
+}
+
+func (c Cached) cache() *cache.Cache {
+	if c.Cache != nil {
+		return c.Cache
+	}
+	return cache.Default()
+}
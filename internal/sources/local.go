@@ -0,0 +1,83 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/FGasquez/alum-bot/internal/helpers"
+	"github.com/FGasquez/alum-bot/internal/types"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+const defaultLocalType = "inamovible"
+
+type localHoliday struct {
+	Date string `yaml:"date"`
+	Name string `yaml:"name"`
+	Type string `yaml:"type"`
+}
+
+type localHolidays struct {
+	Name     string         `yaml:"name"`
+	Holidays []localHoliday `yaml:"holidays"`
+}
+
+// LocalFile reads the holidays of a country from a yaml file, in the same
+// format as the region files: dates are either yyyy-mm-dd, for that year only,
+// or mm-dd, repeating every year.
+type LocalFile struct {
+	Path string
+}
+
+func (l LocalFile) Holidays(_ context.Context, year int) ([]types.Holiday, error) {
+	data, err := os.ReadFile(l.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file localHolidays
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", l.Path, err)
+	}
+
+	var holidays []types.Holiday
+	for _, h := range file.Holidays {
+		if !helpers.ValidHolidayDate(h.Date) {
+			logrus.Warnf("Invalid date %q in %s", h.Date, l.Path)
+			continue
+		}
+		date, ok := helpers.DateForYear(h.Date, year)
+		if !ok {
+			continue
+		}
+
+		holidayType := h.Type
+		if holidayType == "" {
+			holidayType = defaultLocalType
+		}
+
+		holidays = append(holidays, types.Holiday{
+			Date: date,
+			Type: holidayType,
+			Name: h.Name,
+		})
+	}
+
+	return holidays, nil
+}
+
+// Generation returns the modification time of the file, the same for every year
+func (l LocalFile) Generation(_ int) (uint64, bool) {
+	return modTime(l.Path)
+}
+
+// modTime returns the modification time of a file as a generation
+func modTime(path string) (uint64, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, false
+	}
+	return uint64(info.ModTime().UnixNano()), true
+}
@@ -0,0 +1,81 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/FGasquez/alum-bot/internal/types"
+)
+
+// nagerHoliday is an entry of the Nager.Date PublicHolidays response
+type nagerHoliday struct {
+	Date        string   `json:"date"`
+	LocalName   string   `json:"localName"`
+	Name        string   `json:"name"`
+	CountryCode string   `json:"countryCode"`
+	Global      bool     `json:"global"`
+	Counties    []string `json:"counties"`
+	Types       []string `json:"types"`
+}
+
+// ParseNager converts a Nager.Date PublicHolidays response into holidays.
+// Holidays that only apply to some counties are left out.
+func ParseNager(data []byte) ([]types.Holiday, error) {
+	var entries []nagerHoliday
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+
+	holidays := make([]types.Holiday, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.Global {
+			continue
+		}
+
+		name := entry.LocalName
+		if name == "" {
+			name = entry.Name
+		}
+
+		holidayType := defaultLocalType
+		if len(entry.Types) > 0 {
+			holidayType = strings.ToLower(entry.Types[0])
+		}
+
+		holidays = append(holidays, types.Holiday{
+			Date: entry.Date,
+			Type: holidayType,
+			Name: name,
+		})
+	}
+
+	return holidays, nil
+}
+
+// NagerFile reads the holidays of a country from <year>.json files saved from
+// the Nager.Date PublicHolidays endpoint, so no network access is needed
+type NagerFile struct {
+	Dir string
+}
+
+func (n NagerFile) Holidays(_ context.Context, year int) ([]types.Holiday, error) {
+	data, err := os.ReadFile(n.path(year))
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseNager(data)
+}
+
+// Generation returns the modification time of the file of the year
+func (n NagerFile) Generation(year int) (uint64, bool) {
+	return modTime(n.path(year))
+}
+
+func (n NagerFile) path(year int) string {
+	return filepath.Join(n.Dir, fmt.Sprintf("%d.json", year))
+}
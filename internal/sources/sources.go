@@ -0,0 +1,125 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/FGasquez/alum-bot/internal/types"
+	"github.com/sirupsen/logrus"
+)
+
+// Provider returns the national holidays of a country
+type Provider interface {
+	Holidays(ctx context.Context, year int) ([]types.Holiday, error)
+}
+
+var (
+	mu        sync.RWMutex
+	providers = map[string]Provider{}
+)
+
+// NormalizeCountry returns the registry key for a country code
+func NormalizeCountry(country string) string {
+	return strings.ToUpper(strings.TrimSpace(country))
+}
+
+// Register sets the provider used for a country, replacing any previous one
+func Register(country string, provider Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+	providers[NormalizeCountry(country)] = provider
+}
+
+// Unregister removes the provider of a country
+func Unregister(country string) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(providers, NormalizeCountry(country))
+}
+
+// Get returns the provider registered for a country
+func Get(country string) (Provider, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	provider, ok := providers[NormalizeCountry(country)]
+	if !ok {
+		return nil, fmt.Errorf("no holiday source for country %q", country)
+	}
+	return provider, nil
+}
+
+// Countries returns the codes of all the registered countries, sorted
+func Countries() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	countries := make([]string, 0, len(providers))
+	for country := range providers {
+		countries = append(countries, country)
+	}
+	sort.Strings(countries)
+	return countries
+}
+
+// RegisterDir registers a provider for every country found in dir. A
+// <cc>.yaml file registers a LocalFile provider, and a <cc> directory with
+// <year>.json files in Nager.Date format registers a NagerFile provider.
+func RegisterDir(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logrus.WithError(err).Warn("Failed to read holiday sources directory")
+		}
+		return
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		switch {
+		case entry.IsDir():
+			Register(entry.Name(), NagerFile{Dir: path})
+		case filepath.Ext(entry.Name()) == ".yaml":
+			Register(strings.TrimSuffix(entry.Name(), ".yaml"), LocalFile{Path: path})
+		default:
+			continue
+		}
+		logrus.WithField("source", path).Info("Registered holiday source")
+	}
+}
+
+// IsCached reports whether the holidays of a country for the year can be
+// answered without reaching a remote provider. Local providers always can.
+func IsCached(country string, year int) bool {
+	provider, err := Get(country)
+	if err != nil {
+		return true
+	}
+
+	if cached, ok := provider.(interface{ IsCached(year int) bool }); ok {
+		return cached.IsCached(year)
+	}
+	return true
+}
+
+// Generation returns a number that changes whenever the holidays of a country
+// for the year change, so what is built from them can be reused until then. It
+// is false when the provider cannot tell, or the holidays must be fetched.
+func Generation(country string, year int) (uint64, bool) {
+	provider, err := Get(country)
+	if err != nil {
+		return 0, false
+	}
+
+	if generation, ok := provider.(interface {
+		Generation(year int) (uint64, bool)
+	}); ok {
+		return generation.Generation(year)
+	}
+	return 0, false
+}
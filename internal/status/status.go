@@ -0,0 +1,192 @@
+package status
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/FGasquez/alum-bot/internal/clock"
+	holidays "github.com/FGasquez/alum-bot/internal/commands/holiday"
+	"github.com/FGasquez/alum-bot/internal/config"
+	"github.com/FGasquez/alum-bot/internal/helpers"
+	"github.com/FGasquez/alum-bot/internal/messages"
+	"github.com/bwmarrin/discordgo"
+	"github.com/sirupsen/logrus"
+)
+
+// fetchTimeout bounds the retrieval of the holidays shown in a status
+const fetchTimeout = time.Minute
+
+var activityTypes = map[string]discordgo.ActivityType{
+	"custom":    discordgo.ActivityTypeCustom,
+	"playing":   discordgo.ActivityTypeGame,
+	"watching":  discordgo.ActivityTypeWatching,
+	"listening": discordgo.ActivityTypeListening,
+	"competing": discordgo.ActivityTypeCompeting,
+}
+
+// Updater is the part of a discord session used to set the activity status
+type Updater interface {
+	UpdateStatusComplex(usd discordgo.UpdateStatusData) error
+}
+
+// Rotator shows the activity status messages one after the other. Every
+// message is rendered again when it is shown and at local midnight, so the
+// days left change with the day.
+type Rotator struct {
+	Session Updater
+	Scope   holidays.Scope
+	// Keys of the messages to rotate through, a message rendering empty is skipped
+	Keys         []string
+	Interval     time.Duration
+	ActivityType discordgo.ActivityType
+
+	current int
+}
+
+// NewRotator returns a rotator configured from the status flags
+func NewRotator(session Updater, scope holidays.Scope) *Rotator {
+	activityType, ok := activityTypes[config.GetStatusActivityType()]
+	if !ok {
+		logrus.WithField("type", config.GetStatusActivityType()).Warn("Unknown activity type, using custom")
+		activityType = discordgo.ActivityTypeCustom
+	}
+
+	keys := config.GetStatusMessages()
+	if len(keys) == 0 {
+		keys = []string{messages.MessageKeys.ActivityStatus}
+	}
+
+	interval := config.GetStatusInterval()
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+
+	return &Rotator{
+		Session:      session,
+		Scope:        scope,
+		Keys:         keys,
+		Interval:     interval,
+		ActivityType: activityType,
+	}
+}
+
+// Run updates the status until ctx is cancelled, then clears it
+func (r *Rotator) Run(ctx context.Context) error {
+	r.Update(ctx, false)
+
+	rotateAt := clock.Now().Add(r.Interval)
+	for {
+		now := clock.Now()
+		wake := rotateAt
+		if midnight := nextMidnight(now); midnight.Before(wake) {
+			wake = midnight
+		}
+
+		timer := time.NewTimer(wake.Sub(now))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			Set(r.Session, r.ActivityType, "")
+			return nil
+		}
+
+		if clock.Now().Before(rotateAt) {
+			// the day changed, render the same message again
+			r.Update(ctx, false)
+			continue
+		}
+		r.Update(ctx, true)
+		rotateAt = clock.Now().Add(r.Interval)
+	}
+}
+
+// Update sets the status from the current message, or from the next one when
+// advance is set. The current status is kept when the holidays cannot be retrieved.
+func (r *Rotator) Update(ctx context.Context, advance bool) {
+	if len(r.Keys) == 0 {
+		return
+	}
+
+	values, err := Values(ctx, r.Scope)
+	if err != nil && ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		logrus.WithError(err).Error("Error getting holidays for the activity status")
+		return
+	}
+
+	if advance {
+		r.current = (r.current + 1) % len(r.Keys)
+	}
+
+	for tries := 0; tries < len(r.Keys); tries++ {
+		key := r.Keys[r.current]
+		message := strings.TrimSpace(messages.Render(ctx, key, values))
+		if message != "" {
+			Set(r.Session, r.ActivityType, message)
+			return
+		}
+		logrus.WithField("key", key).Debug("Activity status message is empty, skipping it")
+		r.current = (r.current + 1) % len(r.Keys)
+	}
+}
+
+// Values retrieves the holidays shown by the status messages of a scope
+func Values(ctx context.Context, scope holidays.Scope) (messages.Context, error) {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	now := clock.Now()
+	daysLeft, next, _, err := holidays.DaysLeft(ctx, scope, true, false)
+	if err != nil {
+		return messages.Context{}, err
+	}
+	index, err := holidays.GetIndex(ctx, scope, now.Year())
+	if err != nil {
+		return messages.Context{}, err
+	}
+	longWeekend, err := holidays.GetNextLargeHoliday(ctx, scope, holidays.DefaultLongWeekendMinDays)
+	if err != nil {
+		return messages.Context{}, err
+	}
+
+	values := messages.NewContext(ctx,
+		messages.Holiday(next, daysLeft),
+		holidays.Describe(scope, next),
+		messages.Month(helpers.MonthsToSpanish(int64(now.Month())), index.Month(holidays.Months(now.Month()), now)),
+	)
+	if today, ok := index.Get(now.Format("2006-01-02"), now); ok {
+		values.TodayHoliday = today.Name
+	}
+	values.LongWeekend = longWeekend
+
+	return values, nil
+}
+
+// Set sets the activity status, an empty message clears it
+func Set(session Updater, activityType discordgo.ActivityType, message string) {
+	logrus.WithField("message", message).Debug("Setting activity status")
+
+	activity := &discordgo.Activity{
+		Name: message,
+		Type: activityType,
+	}
+	if activityType == discordgo.ActivityTypeCustom {
+		activity.State = message
+	}
+
+	err := session.UpdateStatusComplex(discordgo.UpdateStatusData{
+		Activities: []*discordgo.Activity{activity},
+	})
+	if err != nil {
+		logrus.WithError(err).Error("Error setting activity status")
+	}
+}
+
+func nextMidnight(now time.Time) time.Time {
+	year, month, day := now.Date()
+	return time.Date(year, month, day+1, 0, 0, 0, 0, now.Location())
+}
@@ -1,7 +1,10 @@
 package types
 
 const (
-	Weekend = "weekend"
+	Weekend    = "weekend"
+	Bridge     = "puente"
+	Provincial = "provincial"
+	Custom     = "custom"
 )
 
 // raw holiday
@@ -25,8 +28,21 @@ type ParsedHolidays struct {
 	DaysLeftToHoliday int
 }
 
+// LongWeekend is a run of consecutive days off made of holidays, bridge days
+// and the weekends around them.
+type LongWeekend struct {
+	Start      ParsedHolidays
+	End        ParsedHolidays
+	Length     int
+	Holidays   []ParsedHolidays
+	BridgeDays []ParsedHolidays
+	Days       []ParsedHolidays
+	DaysLeft   int
+}
+
 type ProcessedHolidays struct {
-	Next     ParsedHolidays
-	Previous ParsedHolidays
-	All      []ParsedHolidays
+	Next         ParsedHolidays
+	Previous     ParsedHolidays
+	All          []ParsedHolidays
+	LongWeekends []LongWeekend
 }
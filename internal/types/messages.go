@@ -10,23 +10,3 @@ type RawDate struct {
 	Month int
 	Year  int
 }
-
-type TemplateValues struct {
-	HolidayName   string
-	HolidayList   []ParsedHolidays
-	DaysLeft      int
-	FormattedDate string
-	NamedDate     NamedDate
-	RawDate       RawDate
-	FullDate      string
-	IsToday       bool
-	Length        int
-	Adjacents     []ParsedHolidays
-}
-
-type MonthTemplateValues struct {
-	Month        string
-	Count        int
-	HolidaysList []ParsedHolidays
-	Adjacents    [][]ParsedHolidays
-}